@@ -0,0 +1,18 @@
+// Command slogvet checks the usage of slog.Logger. It is meant to be run by go vet:
+//
+//	go install github.com/edwingeng/slog/analysis/cmd/slogvet@latest
+//	go vet -vettool=$(which slogvet) ./...
+package main
+
+import (
+	"github.com/edwingeng/slog/analysis/kvcheck"
+	"github.com/edwingeng/slog/analysis/printfcheck"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+func main() {
+	unitchecker.Main(
+		kvcheck.Analyzer,
+		printfcheck.Analyzer,
+	)
+}
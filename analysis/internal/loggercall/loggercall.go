@@ -0,0 +1,76 @@
+// Package loggercall recognizes method calls on slog.Logger and its implementations.
+package loggercall
+
+import (
+	"go/ast"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+)
+
+// SlogPath is the import path of the slog package.
+const SlogPath = "github.com/edwingeng/slog"
+
+// LoggerInterface returns the slog.Logger interface if pkg depends on the slog package,
+// or nil otherwise.
+func LoggerInterface(pkg *types.Package) *types.Interface {
+	visited := make(map[*types.Package]bool)
+	var find func(p *types.Package) *types.Interface
+	find = func(p *types.Package) *types.Interface {
+		if visited[p] {
+			return nil
+		}
+		visited[p] = true
+		if p.Path() == SlogPath {
+			if obj, ok := p.Scope().Lookup("Logger").(*types.TypeName); ok {
+				if iface, ok := obj.Type().Underlying().(*types.Interface); ok {
+					return iface
+				}
+			}
+			return nil
+		}
+		for _, imp := range p.Imports() {
+			if iface := find(imp); iface != nil {
+				return iface
+			}
+		}
+		return nil
+	}
+	return find(pkg)
+}
+
+// Method returns the name of the slog.Logger method invoked by call. ok is false if call
+// does not invoke a method of slog.Logger on a value implementing it.
+func Method(pass *analysis.Pass, iface *types.Interface, call *ast.CallExpr) (name string, ok bool) {
+	if iface == nil {
+		return "", false
+	}
+	selExpr, ok := call.Fun.(*ast.SelectorExpr)
+	if !ok {
+		return "", false
+	}
+	sel, ok := pass.TypesInfo.Selections[selExpr]
+	if !ok || sel.Kind() != types.MethodVal {
+		return "", false
+	}
+
+	name = sel.Obj().Name()
+	var found bool
+	for i := 0; i < iface.NumMethods(); i++ {
+		if iface.Method(i).Name() == name {
+			found = true
+			break
+		}
+	}
+	if !found {
+		return "", false
+	}
+
+	recv := sel.Recv()
+	if types.Implements(recv, iface) {
+		return name, true
+	}
+	if _, isPtr := recv.Underlying().(*types.Pointer); !isPtr && types.Implements(types.NewPointer(recv), iface) {
+		return name, true
+	}
+	return "", false
+}
@@ -0,0 +1,182 @@
+// Package kvcheck defines an Analyzer that checks the key-value pairs passed to the
+// w-methods and NewLoggerWith of slog.Logger.
+package kvcheck
+
+import (
+	"bytes"
+	"fmt"
+	"github.com/edwingeng/slog/analysis/internal/loggercall"
+	"go/ast"
+	"go/constant"
+	"go/format"
+	"go/token"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"strconv"
+)
+
+const doc = `check the key-value pairs passed to slog.Logger
+
+The kvcheck analyzer reports calls to Debugw, Infow, Warnw, Errorw and
+NewLoggerWith of slog.Logger and its implementations that pass a dangling
+key, a key that is not a string, or the same constant key more than once.
+Such mistakes are otherwise only caught at runtime. The rest of a call is
+not checked once an argument has an interface type, as its dynamic value
+may be a zap.Field or an error that takes no value.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "kvcheck",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+// firstKeyVal maps the checked methods to the index of their first key-value argument.
+var firstKeyVal = map[string]int{
+	"Debugw":        1,
+	"Infow":         1,
+	"Warnw":         1,
+	"Errorw":        1,
+	"NewLoggerWith": 0,
+}
+
+const zapcorePath = "go.uber.org/zap/zapcore"
+
+func run(pass *analysis.Pass) (any, error) {
+	iface := loggercall.LoggerInterface(pass.Pkg)
+	if iface == nil {
+		return nil, nil
+	}
+
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	ins.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		name, ok := loggercall.Method(pass, iface, call)
+		if !ok {
+			return
+		}
+		start, ok := firstKeyVal[name]
+		if !ok || call.Ellipsis.IsValid() || len(call.Args) <= start {
+			return
+		}
+		checkKeyVals(pass, name, call, start)
+	})
+	return nil, nil
+}
+
+// isZapField reports whether t is zapcore.Field, which zap.Field is an alias of.
+func isZapField(t types.Type) bool {
+	named, ok := types.Unalias(t).(*types.Named)
+	if !ok {
+		return false
+	}
+	obj := named.Obj()
+	return obj.Pkg() != nil && obj.Pkg().Path() == zapcorePath && obj.Name() == "Field"
+}
+
+// isStringKey reports whether a key is a string at runtime, i.e. its type is string
+// or it is an untyped string constant. Values of other types whose underlying type
+// is string are not keys to zap.
+func isStringKey(tv types.TypeAndValue) bool {
+	return types.Identical(tv.Type, types.Typ[types.String]) || types.Identical(tv.Type, types.Typ[types.UntypedString])
+}
+
+var errorType = types.Universe.Lookup("error").Type().Underlying().(*types.Interface)
+
+func checkKeyVals(pass *analysis.Pass, name string, call *ast.CallExpr, start int) {
+	args := call.Args
+	seen := make(map[string]bool)
+	for i := start; i < len(args); {
+		t := pass.TypesInfo.TypeOf(args[i])
+		if t == nil {
+			return
+		}
+		if isZapField(t) || types.Implements(t, errorType) {
+			i++
+			continue
+		}
+		if types.IsInterface(t) {
+			// The dynamic value may be a field or an error, so the pairing of the rest
+			// of the arguments is unknown.
+			return
+		}
+
+		if i == len(args)-1 {
+			pass.Report(analysis.Diagnostic{
+				Pos:     args[i].Pos(),
+				End:     args[i].End(),
+				Message: fmt.Sprintf("%s call has a key without a value: %s", name, render(pass.Fset, args[i])),
+				SuggestedFixes: []analysis.SuggestedFix{{
+					Message: "Remove the dangling key",
+					TextEdits: []analysis.TextEdit{{
+						Pos: removalStart(args, i, call),
+						End: args[i].End(),
+					}},
+				}},
+			})
+			return
+		}
+
+		key := args[i]
+		tv := pass.TypesInfo.Types[key]
+		if !isStringKey(tv) {
+			d := analysis.Diagnostic{
+				Pos:     key.Pos(),
+				End:     key.End(),
+				Message: fmt.Sprintf("%s call has a non-string key: %s", name, render(pass.Fset, key)),
+			}
+			if tv.Value != nil {
+				str := tv.Value.ExactString()
+				if tv.Value.Kind() == constant.String {
+					str = constant.StringVal(tv.Value)
+				}
+				d.SuggestedFixes = []analysis.SuggestedFix{{
+					Message: "Convert the key to a string literal",
+					TextEdits: []analysis.TextEdit{{
+						Pos:     key.Pos(),
+						End:     key.End(),
+						NewText: []byte(strconv.Quote(str)),
+					}},
+				}}
+			}
+			pass.Report(d)
+		} else if tv.Value != nil && tv.Value.Kind() == constant.String {
+			str := constant.StringVal(tv.Value)
+			if seen[str] {
+				pass.Report(analysis.Diagnostic{
+					Pos:     key.Pos(),
+					End:     args[i+1].End(),
+					Message: fmt.Sprintf("%s call has a duplicate key: %q", name, str),
+					SuggestedFixes: []analysis.SuggestedFix{{
+						Message: "Remove the duplicate key-value pair",
+						TextEdits: []analysis.TextEdit{{
+							Pos: removalStart(args, i, call),
+							End: args[i+1].End(),
+						}},
+					}},
+				})
+			}
+			seen[str] = true
+		}
+		i += 2
+	}
+}
+
+// removalStart returns the position from which args[i:] can be removed along with the
+// preceding comma.
+func removalStart(args []ast.Expr, i int, call *ast.CallExpr) token.Pos {
+	if i > 0 {
+		return args[i-1].End()
+	}
+	return call.Lparen + 1
+}
+
+func render(fset *token.FileSet, x ast.Expr) string {
+	var buf bytes.Buffer
+	if err := format.Node(&buf, fset, x); err != nil {
+		return "?"
+	}
+	return buf.String()
+}
@@ -0,0 +1,11 @@
+package kvcheck_test
+
+import (
+	"github.com/edwingeng/slog/analysis/kvcheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"testing"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), kvcheck.Analyzer, "a")
+}
@@ -0,0 +1,40 @@
+package a
+
+import (
+	"errors"
+
+	"github.com/edwingeng/slog"
+	"go.uber.org/zap"
+)
+
+type HandlerContext struct {
+	slog.Logger
+}
+
+type other struct{}
+
+func (other) Infow(msg string, keyVals ...any) {}
+
+const keyUser = "user"
+
+type Key string
+
+const KeyUser Key = "user"
+
+func f(logger slog.Logger, zl *slog.ZapLogger, ctx *HandlerContext, kvs []any) {
+	logger.Infow("ok", "foo", 1, "bar", 2)
+	logger.Infow("ok", zap.Int("foo", 1), errors.New("x"), "bar", 2)
+	logger.Infow("ok", kvs...)
+	logger.Infow("dangling", "foo", 1, "bar") // want `Infow call has a key without a value: "bar"`
+	zl.Warnw("non-string", 100, "foo")        // want `Warnw call has a non-string key: 100`
+	ctx.Errorw("dup", keyUser, 1, "user", 2)  // want `Errorw call has a duplicate key: "user"`
+	logger.NewLoggerWith("handler")           // want `NewLoggerWith call has a key without a value: "handler"`
+
+	logger.Infow("named", KeyUser, 1) // want `Infow call has a non-string key: KeyUser`
+
+	var key any = "foo"
+	logger.Debugw("dynamic", key, 1)
+	logger.Debugw("dynamic", key, 1, 2)
+
+	other{}.Infow("ignored", 100)
+}
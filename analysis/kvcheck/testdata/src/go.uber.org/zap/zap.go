@@ -0,0 +1,7 @@
+package zap
+
+import "go.uber.org/zap/zapcore"
+
+type Field = zapcore.Field
+
+func Int(key string, val int) Field { return Field{Key: key} }
@@ -0,0 +1,297 @@
+// Package printfcheck defines an Analyzer that checks the format strings passed to the
+// f-methods of slog.Logger.
+package printfcheck
+
+import (
+	"fmt"
+	"github.com/edwingeng/slog/analysis/internal/loggercall"
+	"go/ast"
+	"go/constant"
+	"go/types"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/inspect"
+	"golang.org/x/tools/go/ast/inspector"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const doc = `check the format strings passed to slog.Logger
+
+The printfcheck analyzer treats Debugf, Infof, Warnf and Errorf of slog.Logger
+and its implementations as printf wrappers, which go vet does not recognize
+when they are called through the interface. It reports format strings that do
+not match their arguments, and Errorf calls with a non-constant format string.`
+
+var Analyzer = &analysis.Analyzer{
+	Name:     "printfcheck",
+	Doc:      doc,
+	Requires: []*analysis.Analyzer{inspect.Analyzer},
+	Run:      run,
+}
+
+var printfMethods = map[string]bool{
+	"Debugf": true,
+	"Infof":  true,
+	"Warnf":  true,
+	"Errorf": true,
+}
+
+func run(pass *analysis.Pass) (any, error) {
+	iface := loggercall.LoggerInterface(pass.Pkg)
+	if iface == nil {
+		return nil, nil
+	}
+
+	ins := pass.ResultOf[inspect.Analyzer].(*inspector.Inspector)
+	ins.Preorder([]ast.Node{(*ast.CallExpr)(nil)}, func(n ast.Node) {
+		call := n.(*ast.CallExpr)
+		name, ok := loggercall.Method(pass, iface, call)
+		if !ok || !printfMethods[name] || len(call.Args) == 0 {
+			return
+		}
+		checkCall(pass, name, call)
+	})
+	return nil, nil
+}
+
+func checkCall(pass *analysis.Pass, name string, call *ast.CallExpr) {
+	formatArg := call.Args[0]
+	tv := pass.TypesInfo.Types[formatArg]
+	if tv.Value == nil || tv.Value.Kind() != constant.String {
+		if name == "Errorf" {
+			d := analysis.Diagnostic{
+				Pos:     formatArg.Pos(),
+				End:     formatArg.End(),
+				Message: "non-constant format string in call to Errorf",
+			}
+			if len(call.Args) == 1 {
+				d.SuggestedFixes = []analysis.SuggestedFix{{
+					Message: `Insert "%s" format string`,
+					TextEdits: []analysis.TextEdit{{
+						Pos:     formatArg.Pos(),
+						End:     formatArg.Pos(),
+						NewText: []byte(`"%s", `),
+					}},
+				}}
+			}
+			pass.Report(d)
+		}
+		return
+	}
+	if call.Ellipsis.IsValid() {
+		return
+	}
+
+	format := constant.StringVal(tv.Value)
+	args := call.Args[1:]
+	directives, err := parseFormat(format)
+	if err != nil {
+		pass.Reportf(formatArg.Pos(), "%s format %s", name, err)
+		return
+	}
+
+	maxArg := 0
+	for _, d := range directives {
+		for _, argIdx := range d.stars {
+			if argIdx >= len(args) {
+				pass.Reportf(call.Pos(), "%s format %s reads arg #%d, but call has %s", name, d.text, argIdx+1, count(len(args), "arg"))
+				return
+			}
+			if !isInteger(pass.TypesInfo.TypeOf(args[argIdx])) {
+				pass.Reportf(args[argIdx].Pos(), "%s format %s uses non-int %s as argument of *", name, d.text, render(args[argIdx]))
+			}
+			maxArg = max(maxArg, argIdx+1)
+		}
+		if d.verb == '%' {
+			continue
+		}
+		if d.arg >= len(args) {
+			pass.Reportf(call.Pos(), "%s format %s reads arg #%d, but call has %s", name, d.text, d.arg+1, count(len(args), "arg"))
+			return
+		}
+		maxArg = max(maxArg, d.arg+1)
+		if t := pass.TypesInfo.TypeOf(args[d.arg]); t != nil && !matchVerb(d.verb, t) {
+			pass.Reportf(args[d.arg].Pos(), "%s format %s has arg %s of wrong type %s", name, d.text, render(args[d.arg]), t)
+		}
+	}
+
+	if maxArg < len(args) {
+		if len(directives) == 0 {
+			pass.Reportf(call.Pos(), "%s call has arguments but no formatting directives", name)
+		} else {
+			pass.Reportf(call.Pos(), "%s call needs %s but has %s", name, count(maxArg, "arg"), count(len(args), "arg"))
+		}
+	}
+}
+
+func count(n int, what string) string {
+	if n == 1 {
+		return "1 " + what
+	}
+	return fmt.Sprintf("%d %ss", n, what)
+}
+
+func render(x ast.Expr) string {
+	return types.ExprString(x)
+}
+
+type directive struct {
+	text  string
+	verb  rune
+	arg   int
+	stars []int
+}
+
+// parseFormat parses the formatting directives in format the same way as package fmt.
+func parseFormat(format string) ([]directive, error) {
+	var ret []directive
+	argNum := 0
+	for i := 0; i < len(format); {
+		if format[i] != '%' {
+			i++
+			continue
+		}
+		start := i
+		i++
+
+		var d directive
+		for i < len(format) && strings.IndexByte("+-# 0", format[i]) >= 0 {
+			i++
+		}
+
+		parseIndex := func() error {
+			if i >= len(format) || format[i] != '[' {
+				return nil
+			}
+			end := strings.IndexByte(format[i:], ']')
+			if end < 0 {
+				return fmt.Errorf("%s is missing closing ]", format[start:])
+			}
+			n, err := strconv.Atoi(format[i+1 : i+end])
+			if err != nil || n < 1 {
+				return fmt.Errorf("%s has invalid argument index [%s]", format[start:i+end+1], format[i+1:i+end])
+			}
+			argNum = n - 1
+			i += end + 1
+			return nil
+		}
+		parseNumber := func() error {
+			if err := parseIndex(); err != nil {
+				return err
+			}
+			if i < len(format) && format[i] == '*' {
+				d.stars = append(d.stars, argNum)
+				argNum++
+				i++
+				return nil
+			}
+			for i < len(format) && '0' <= format[i] && format[i] <= '9' {
+				i++
+			}
+			return nil
+		}
+
+		if err := parseNumber(); err != nil {
+			return nil, err
+		}
+		if i < len(format) && format[i] == '.' {
+			i++
+			if err := parseNumber(); err != nil {
+				return nil, err
+			}
+		}
+		if err := parseIndex(); err != nil {
+			return nil, err
+		}
+		if i >= len(format) {
+			return nil, fmt.Errorf("%s is missing verb at end of string", format[start:])
+		}
+
+		verb, size := utf8.DecodeRuneInString(format[i:])
+		i += size
+		d.text = format[start:i]
+		d.verb = verb
+		if verb != '%' {
+			if !strings.ContainsRune(knownVerbs, verb) {
+				return nil, fmt.Errorf("%s has unknown verb %c", d.text, verb)
+			}
+			d.arg = argNum
+			argNum++
+		}
+		ret = append(ret, d)
+	}
+	return ret, nil
+}
+
+const knownVerbs = "bcdeEfFgGoOpqstTUvxX"
+
+func isInteger(t types.Type) bool {
+	if t == nil {
+		return true
+	}
+	basic, ok := t.Underlying().(*types.Basic)
+	return ok && basic.Info()&types.IsInteger != 0
+}
+
+// hasMethod reports whether the method set of t contains a method called name.
+func hasMethod(t types.Type, name string) bool {
+	obj, _, _ := types.LookupFieldOrMethod(t, false, nil, name)
+	_, ok := obj.(*types.Func)
+	return ok
+}
+
+// matchVerb reports whether an argument of type t is acceptable for verb. Only basic
+// types are checked; anything else is assumed to be fine.
+func matchVerb(verb rune, t types.Type) bool {
+	if verb == 'v' || verb == 'T' {
+		return true
+	}
+	if _, ok := t.Underlying().(*types.Interface); ok {
+		return true
+	}
+	if hasMethod(t, "Format") {
+		return true
+	}
+	if verb == 's' || verb == 'q' || verb == 'x' || verb == 'X' {
+		if hasMethod(t, "Error") || hasMethod(t, "String") {
+			return true
+		}
+	}
+
+	basic, ok := t.Underlying().(*types.Basic)
+	if !ok {
+		if verb == 'p' {
+			switch t.Underlying().(type) {
+			case *types.Pointer, *types.Slice, *types.Map, *types.Chan, *types.Signature:
+				return true
+			}
+			return false
+		}
+		return true
+	}
+
+	info := basic.Info()
+	switch verb {
+	case 't':
+		return info&types.IsBoolean != 0
+	case 'c', 'U':
+		return info&types.IsInteger != 0
+	case 'd', 'o', 'O':
+		return info&types.IsInteger != 0
+	case 'b':
+		return info&(types.IsInteger|types.IsFloat|types.IsComplex) != 0
+	case 'e', 'E', 'f', 'F', 'g', 'G':
+		return info&(types.IsFloat|types.IsComplex) != 0
+	case 'x', 'X':
+		return info&(types.IsInteger|types.IsFloat|types.IsComplex|types.IsString) != 0
+	case 's', 'q':
+		if verb == 'q' && info&types.IsInteger != 0 {
+			return true
+		}
+		return info&types.IsString != 0
+	case 'p':
+		return basic.Kind() == types.UnsafePointer
+	}
+	return true
+}
@@ -0,0 +1,11 @@
+package printfcheck_test
+
+import (
+	"github.com/edwingeng/slog/analysis/printfcheck"
+	"golang.org/x/tools/go/analysis/analysistest"
+	"testing"
+)
+
+func TestAnalyzer(t *testing.T) {
+	analysistest.RunWithSuggestedFixes(t, analysistest.TestData(), printfcheck.Analyzer, "a")
+}
@@ -0,0 +1,38 @@
+package a
+
+import (
+	"errors"
+	"time"
+
+	"github.com/edwingeng/slog"
+)
+
+type HandlerContext struct {
+	slog.Logger
+}
+
+type stringer struct{}
+
+func (stringer) String() string { return "" }
+
+func f(logger slog.Logger, zl *slog.ZapLogger, ctx *HandlerContext, msg string, args []any) {
+	logger.Infof("%d %s %v %5.2f %%", 1, "x", struct{}{}, 3.14)
+	logger.Infof("%[2]d %[1]s", "x", 1)
+	logger.Debugf("%*d", 5, 1)
+	logger.Warnf("%s %x %q", errors.New("x"), "abc", 'c')
+	logger.Infof("%s %d", stringer{}, time.Second)
+	logger.Infof(msg, args...)
+	logger.Debugf(msg)
+
+	logger.Infof("%d %d", 1)    // want `Infof format %d reads arg #2, but call has 1 arg`
+	zl.Warnf("%d", 1, 2)        // want `Warnf call needs 1 arg but has 2 args`
+	ctx.Debugf("hello", 1)      // want `Debugf call has arguments but no formatting directives`
+	logger.Infof("%d", "x")     // want `Infof format %d has arg "x" of wrong type string`
+	logger.Infof("%t", 1)       // want `Infof format %t has arg 1 of wrong type int`
+	logger.Infof("%z", 1)       // want `Infof format %z has unknown verb z`
+	logger.Infof("%*d", "x", 1) // want `Infof format %\*d uses non-int "x" as argument of \*`
+	logger.Errorf(msg)          // want `non-constant format string in call to Errorf`
+	logger.Errorf(msg, 1)       // want `non-constant format string in call to Errorf`
+	logger.Infof("%[3]d", 1, 2) // want `Infof format %\[3\]d reads arg #3, but call has 2 args`
+	logger.Infof("100%")        // want `Infof format % is missing verb at end of string`
+}
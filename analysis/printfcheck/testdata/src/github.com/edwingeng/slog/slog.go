@@ -0,0 +1,45 @@
+package slog
+
+type Logger interface {
+	NewLoggerWith(keyVals ...any) Logger
+	LogLevelEnabled(level int) bool
+
+	Debug(args ...any)
+	Info(args ...any)
+	Warn(args ...any)
+	Error(args ...any)
+
+	Debugf(format string, args ...any)
+	Infof(format string, args ...any)
+	Warnf(format string, args ...any)
+	Errorf(format string, args ...any)
+
+	Debugw(msg string, keyVals ...any)
+	Infow(msg string, keyVals ...any)
+	Warnw(msg string, keyVals ...any)
+	Errorw(msg string, keyVals ...any)
+
+	FlushLogger() error
+}
+
+type ZapLogger struct{}
+
+func (zl *ZapLogger) NewLoggerWith(keyVals ...any) Logger { return zl }
+func (zl *ZapLogger) LogLevelEnabled(level int) bool      { return true }
+
+func (zl *ZapLogger) Debug(args ...any) {}
+func (zl *ZapLogger) Info(args ...any)  {}
+func (zl *ZapLogger) Warn(args ...any)  {}
+func (zl *ZapLogger) Error(args ...any) {}
+
+func (zl *ZapLogger) Debugf(format string, args ...any) {}
+func (zl *ZapLogger) Infof(format string, args ...any)  {}
+func (zl *ZapLogger) Warnf(format string, args ...any)  {}
+func (zl *ZapLogger) Errorf(format string, args ...any) {}
+
+func (zl *ZapLogger) Debugw(msg string, keyVals ...any) {}
+func (zl *ZapLogger) Infow(msg string, keyVals ...any)  {}
+func (zl *ZapLogger) Warnw(msg string, keyVals ...any)  {}
+func (zl *ZapLogger) Errorw(msg string, keyVals ...any) {}
+
+func (zl *ZapLogger) FlushLogger() error { return nil }
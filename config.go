@@ -1,24 +1,127 @@
 package slog
 
 import (
+	"fmt"
 	"go.uber.org/zap"
 	"go.uber.org/zap/zapcore"
 	"golang.org/x/term"
+	"net/url"
 	"os"
+	"sync"
 	"time"
 )
 
+var (
+	extensionRegistry sync.Once
+)
+
+// registerExtensions makes the encoders and sinks of slog available to zap by name.
+// It is called lazily by Config.Build. A name already registered by another package
+// is left to that package. zap rejects nothing else, as the names are constants.
+func registerExtensions() {
+	registerEncoders()
+	registerSinks()
+}
+
+var (
+	slogEncoders = map[string]func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error){
+		EncodingLogfmt: func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+			return NewLogfmtEncoder(cfg), nil
+		},
+		EncodingPretty: func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+			return NewPrettyEncoder(cfg), nil
+		},
+		EncodingECS: func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+			return NewECSEncoder(cfg), nil
+		},
+		EncodingOTel: func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+			return NewOTelEncoder(cfg), nil
+		},
+		EncodingGELF: func(cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+			return NewGELFEncoder(cfg), nil
+		},
+	}
+)
+
+func registerEncoders() {
+	for name, constructor := range slogEncoders {
+		_ = zap.RegisterEncoder(name, constructor)
+	}
+}
+
+// newEncoder creates an encoder by its name in Config.Encoding. Unlike zap, it knows
+// only the encodings of zap and slog.
+func newEncoder(name string, cfg zapcore.EncoderConfig) (zapcore.Encoder, error) {
+	switch name {
+	case "json":
+		return zapcore.NewJSONEncoder(cfg), nil
+	case "console":
+		return zapcore.NewConsoleEncoder(cfg), nil
+	}
+	if constructor, ok := slogEncoders[name]; ok {
+		return constructor(cfg)
+	}
+	return nil, fmt.Errorf("the encoding %q is not supported by the syslog outputs", name)
+}
+
+func registerSinks() {
+	sinkRegistry.once.Do(initRegistry)
+	sinks := map[string]func(u *url.URL) (zap.Sink, error){
+		"gelf+udp":   newGELFUDPSink,
+		"gelf+tcp":   newGELFTCPSink,
+		syslogScheme: newSyslogSink,
+		"ship+tcp":   newShippingSink,
+		"ship+http":  newShippingSink,
+		"ship+https": newShippingSink,
+	}
+	for scheme, factory := range sinks {
+		_ = zap.RegisterSink(scheme, factory)
+	}
+}
+
 type Config struct {
 	zap.Config
+	// Stacktrace configures the stack traces captured by slog. When it is set, zap's own
+	// stack traces are disabled, so DisableStacktrace and zap.AddStacktrace have no
+	// effect. Nil disables it.
+	Stacktrace *StacktraceConfig
+	// StructuredErrors makes the logger render errors as structured fields. See
+	// ZapLoggerStructuredErrors.
+	StructuredErrors bool
 }
 
 func (cfg *Config) Build(opts ...zap.Option) (*ZapLogger, error) {
-	l, err := cfg.Config.Build(opts...)
+	extensionRegistry.Do(registerExtensions)
+	zcfg := cfg.Config
+	paths, syslogCore, err := buildSyslogCore(&zcfg)
+	if err != nil {
+		return nil, err
+	}
+	if syslogCore != nil {
+		// The syslog outputs are teed in before the options of the caller apply.
+		zcfg.OutputPaths = paths
+		opts = append([]zap.Option{zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			if len(paths) == 0 {
+				return syslogCore
+			}
+			return zapcore.NewTee(core, syslogCore)
+		})}, opts...)
+	}
+	if st := cfg.Stacktrace; st != nil {
+		// zap would overwrite the stack traces captured by the core.
+		opts = append(opts, zap.AddStacktrace(zapcore.InvalidLevel), zap.WrapCore(func(core zapcore.Core) zapcore.Core {
+			return newStackCore(core, *st)
+		}))
+	}
+	l, err := zcfg.Build(opts...)
 	if err != nil {
 		return nil, err
 	}
 
 	zsl := l.Sugar()
+	if cfg.StructuredErrors {
+		return NewZapLogger(zsl, ZapLoggerStructuredErrors()), nil
+	}
 	return NewZapLogger(zsl), nil
 }
 
@@ -43,7 +146,7 @@ func NewDevelopmentConfigWith(dateTimeFormat string) *Config {
 	cfg.EncoderConfig.EncodeTime = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 		enc.AppendString(t.Format(dateTimeFormat))
 	}
-	return &Config{cfg}
+	return &Config{Config: cfg}
 }
 
 func NewProductionConfig() *Config {
@@ -52,5 +155,5 @@ func NewProductionConfig() *Config {
 	cfg.EncoderConfig.EncodeTime = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
 		enc.AppendInt64(t.UnixMilli())
 	}
-	return &Config{cfg}
+	return &Config{Config: cfg}
 }
@@ -1,7 +1,11 @@
 package slog
 
 import (
+	"encoding/json"
 	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 )
 
@@ -30,3 +34,107 @@ func TestLogLevelEnabled(t *testing.T) {
 		}
 	}
 }
+
+func TestRegisterExtensions(t *testing.T) {
+	extensionRegistry.Do(registerExtensions)
+	registerExtensions()
+	if _, err := NewProductionConfig().Build(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func buildWithStacktrace(t *testing.T, cfg *Config, st *StacktraceConfig) (*ZapLogger, func() []map[string]any) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	cfg.OutputPaths = []string{path}
+	cfg.Stacktrace = st
+	logger := cfg.MustBuild()
+	return logger, func() []map[string]any {
+		_ = logger.FlushLogger()
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ret []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(string(data)), "\n") {
+			m := make(map[string]any)
+			if err := json.Unmarshal([]byte(line), &m); err != nil {
+				t.Fatal(err)
+			}
+			ret = append(ret, m)
+		}
+		return ret
+	}
+}
+
+func TestConfig_Stacktrace(t *testing.T) {
+	logger, read := buildWithStacktrace(t, NewProductionConfig(), &StacktraceConfig{
+		MaxFrames: 2,
+		Compact:   true,
+	})
+	logger.Warn("no stack")
+	logger.NewLoggerWith("foo", 1).Errorw("with stack")
+
+	a := read()
+	if len(a) != 2 {
+		t.Fatal(`len(a) != 2`)
+	}
+	if _, ok := a[0]["stacktrace"]; ok {
+		t.Fatal("WARN should not have a stack trace")
+	}
+	st, _ := a[1]["stacktrace"].(string)
+	frames := strings.Split(st, " | ")
+	if strings.Contains(st, "\n") || len(frames) != 2 {
+		t.Fatal("the stack trace should be compact and have 2 frames: " + st)
+	}
+	if !strings.HasPrefix(frames[0], "github.com/edwingeng/slog.TestConfig_Stacktrace (") {
+		t.Fatal("the frames of slog and zap should be skipped: " + st)
+	}
+}
+
+func TestConfig_Stacktrace_ZapStacktrace(t *testing.T) {
+	cfg := NewProductionConfig()
+	cfg.DisableStacktrace = false
+	logger, read := buildWithStacktrace(t, cfg, &StacktraceConfig{
+		MaxFrames: 2,
+		Compact:   true,
+	})
+	logger.Error("hello")
+
+	st, _ := read()[0]["stacktrace"].(string)
+	if strings.Contains(st, "\n") || len(strings.Split(st, " | ")) != 2 {
+		t.Fatal("the stack trace of slog should take precedence over that of zap: " + st)
+	}
+}
+
+func TestConfig_Stacktrace_KeepSlogFrames(t *testing.T) {
+	logger, read := buildWithStacktrace(t, NewProductionConfig(), &StacktraceConfig{
+		Level:          zapcore.InfoLevel,
+		KeepSlogFrames: true,
+	})
+	logger.Info("hello")
+
+	st, _ := read()[0]["stacktrace"].(string)
+	if !strings.Contains(st, "github.com/edwingeng/slog.(*ZapLogger).Info\n\t") {
+		t.Fatal("the frames of slog should be kept: " + st)
+	}
+	if !strings.Contains(st, "\ngithub.com/edwingeng/slog.TestConfig_Stacktrace_KeepSlogFrames\n\t") {
+		t.Fatal("something is wrong with the stack trace: " + st)
+	}
+}
+
+func TestConfig_Stacktrace_Sampling(t *testing.T) {
+	logger, read := buildWithStacktrace(t, NewProductionConfig(), &StacktraceConfig{
+		Level: zapcore.InfoLevel,
+	})
+	for i := 0; i < 1000; i++ {
+		logger.Info("same")
+	}
+
+	a := read()
+	if len(a) >= 1000 {
+		t.Fatal("the entries should be sampled")
+	}
+	if _, ok := a[0]["stacktrace"]; !ok {
+		t.Fatal("the sampled entries should have a stack trace")
+	}
+}
@@ -0,0 +1,142 @@
+package slog
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// EntryKind tells ordinary log messages from diagnostic ones.
+type EntryKind int
+
+const (
+	// EntryMessage is a log message logged by the code under test.
+	EntryMessage EntryKind = iota
+	// EntryDiagnostic reports malformed key-value pairs passed to NewLoggerWith or a w-method.
+	EntryDiagnostic
+)
+
+const (
+	_oddNumberErrMsg    = "Ignored key without a value."
+	_nonStringKeyErrMsg = "Ignored key-value pairs with non-string keys."
+	_multipleErrMsg     = "Multiple errors without a key."
+)
+
+type diagnostic struct {
+	msg   string
+	keys  []any
+	field zap.Field
+}
+
+// checkKeyVals validates keyVals the same way as zap.SugaredLogger does. It returns the
+// well-formed fields, which zap accepts silently, and a diagnostic for each problem found.
+// Errors are rendered as structured fields if structuredErrors is true.
+func checkKeyVals(keyVals []any, structuredErrors bool) ([]any, []diagnostic) {
+	if len(keyVals) == 0 {
+		return nil, nil
+	}
+
+	var (
+		fields    = make([]any, 0, len(keyVals))
+		diags     []diagnostic
+		invalid   invalidPairs
+		seenError bool
+	)
+
+	for i := 0; i < len(keyVals); {
+		if f, ok := keyVals[i].(zap.Field); ok {
+			fields = append(fields, f)
+			i++
+			continue
+		}
+
+		if err, ok := keyVals[i].(error); ok {
+			if !seenError {
+				seenError = true
+				if structuredErrors {
+					fields = append(fields, errorField("error", err))
+				} else {
+					fields = append(fields, zap.Error(err))
+				}
+			} else {
+				diags = append(diags, diagnostic{
+					msg:   _multipleErrMsg,
+					keys:  []any{err},
+					field: zap.Error(err),
+				})
+			}
+			i++
+			continue
+		}
+
+		if i == len(keyVals)-1 {
+			diags = append(diags, diagnostic{
+				msg:   _oddNumberErrMsg,
+				keys:  []any{keyVals[i]},
+				field: zap.Any("ignored", keyVals[i]),
+			})
+			break
+		}
+
+		key, val := keyVals[i], keyVals[i+1]
+		if keyStr, ok := key.(string); !ok {
+			invalid = append(invalid, invalidPair{i, key, val})
+		} else if err, ok := val.(error); ok && structuredErrors {
+			fields = append(fields, errorField(keyStr, err))
+		} else {
+			fields = append(fields, zap.Any(keyStr, val))
+		}
+		i += 2
+	}
+
+	if len(invalid) > 0 {
+		keys := make([]any, len(invalid))
+		for i, p := range invalid {
+			keys[i] = p.key
+		}
+		diags = append(diags, diagnostic{
+			msg:   _nonStringKeyErrMsg,
+			keys:  keys,
+			field: zap.Array("invalid", invalid),
+		})
+	}
+	return fields, diags
+}
+
+type invalidPair struct {
+	position   int
+	key, value any
+}
+
+func (p invalidPair) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddInt64("position", int64(p.position))
+	zap.Any("key", p.key).AddTo(enc)
+	zap.Any("value", p.value).AddTo(enc)
+	return nil
+}
+
+type invalidPairs []invalidPair
+
+func (ps invalidPairs) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	var err error
+	for i := range ps {
+		err = enc.AppendObject(ps[i])
+		if err != nil {
+			return err
+		}
+	}
+	return err
+}
+
+// Diagnostics returns the diagnostic entries, which report malformed key-value pairs.
+func (sc *Scavenger) Diagnostics() []LogEntry {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	var ret []LogEntry
+	for _, e := range sc.view() {
+		if e.Kind == EntryDiagnostic {
+			ret = append(ret, e)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,57 @@
+package slog
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestScavenger_Diagnostics(t *testing.T) {
+	var sc = NewScavenger()
+	sc.Infow("1", "foo", 100)
+	sc.Infow("2", 100, "foo")
+	sc.Warnw("3", errors.New("e1"), errors.New("e2"), "foo", 1, "dangling")
+	if len(sc.Diagnostics()) != 3 || sc.Len() != 6 {
+		t.Fatal("Diagnostics does not work as expected")
+	}
+
+	dump := `INFO	1	{"foo": 100}
+ERROR	Ignored key-value pairs with non-string keys.	{"invalid": [{"position": 0, "key": 100, "value": "foo"}]}
+INFO	2
+ERROR	Multiple errors without a key.	{"error": "e2"}
+ERROR	Ignored key without a value.	{"ignored": "dangling"}
+WARN	3	{"error": "e1", "foo": 1}
+`
+	if sc.Dump() != dump {
+		t.Fatal("something is wrong with Dump: " + sc.Dump())
+	}
+
+	diags := sc.Diagnostics()
+	if diags[0].Kind != EntryDiagnostic || len(diags[0].Keys) != 1 || diags[0].Keys[0] != 100 {
+		t.Fatal("Diagnostics does not work as expected")
+	}
+	if diags[2].Keys[0] != "dangling" || sc.LogEntry(0).Kind != EntryMessage {
+		t.Fatal("Diagnostics does not work as expected")
+	}
+	if diags[1].Keys[0].(error).Error() != "e2" {
+		t.Fatal("Diagnostics does not work as expected")
+	}
+}
+
+func TestScavenger_Diagnostics_NewLoggerWith(t *testing.T) {
+	var sc = NewScavenger()
+	child := sc.NewLoggerWith("foo", 1, "bar")
+	grandchild := child.NewLoggerWith("qux", 2)
+	grandchild.Info("hello")
+	child.Info("world")
+
+	dump := `ERROR	Ignored key without a value.	{"ignored": "bar"}
+INFO	hello	{"foo": 1, "qux": 2}
+INFO	world	{"foo": 1}
+`
+	if sc.Dump() != dump {
+		t.Fatal("something is wrong with Dump: " + sc.Dump())
+	}
+	if d := sc.Diagnostics(); len(d) != 1 || d[0].Keys[0] != "bar" {
+		t.Fatal("Diagnostics does not work as expected")
+	}
+}
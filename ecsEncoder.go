@@ -0,0 +1,78 @@
+package slog
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"time"
+)
+
+const (
+	// EncodingECS is the Config.Encoding value that selects the Elastic Common Schema
+	// encoder.
+	EncodingECS = "ecs"
+	// ECSVersion is the version of the Elastic Common Schema written by the ECS encoder.
+	ECSVersion = "8.11.0"
+
+	ecsStackTraceKey = "error.stack_trace"
+)
+
+var (
+	_ zapcore.Encoder = &ecsEncoder{}
+)
+
+type ecsEncoder struct {
+	zapcore.Encoder
+}
+
+// NewECSEncoder creates an encoder that writes JSON documents compliant with the
+// Elastic Common Schema: @timestamp, log.level, message, log.logger, log.origin and
+// ecs.version. The first error of an entry is written as error.message, error.type
+// and error.stack_trace. The stack trace of the entry is written as error.stack_trace
+// only if the error does not carry one. The keys and the time and level encoders of
+// cfg are overridden; the rest of cfg, e.g. EncodeDuration, is respected.
+func NewECSEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	cfg.TimeKey = "@timestamp"
+	cfg.LevelKey = "log.level"
+	cfg.NameKey = "log.logger"
+	cfg.MessageKey = "message"
+	cfg.StacktraceKey = ecsStackTraceKey
+	cfg.CallerKey = ""
+	cfg.FunctionKey = ""
+	cfg.EncodeTime = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(t.UTC().Format("2006-01-02T15:04:05.000Z07:00"))
+	}
+	cfg.EncodeLevel = zapcore.LowercaseLevelEncoder
+	cfg.EncodeName = zapcore.FullNameEncoder
+	return &ecsEncoder{Encoder: zapcore.NewJSONEncoder(cfg)}
+}
+
+func (enc *ecsEncoder) Clone() zapcore.Encoder {
+	return &ecsEncoder{Encoder: enc.Encoder.Clone()}
+}
+
+func (enc *ecsEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	fields = flattenFirstError(fields, "error.message", "error.type", ecsStackTraceKey)
+	for _, f := range fields {
+		if f.Key == ecsStackTraceKey {
+			ent.Stack = ""
+			break
+		}
+	}
+	head := []zapcore.Field{zap.String("ecs.version", ECSVersion)}
+	if ent.Caller.Defined {
+		head = append(head, zap.Object("log.origin", ecsOrigin(ent.Caller)))
+	}
+	return enc.Encoder.EncodeEntry(ent, append(head, fields...))
+}
+
+type ecsOrigin zapcore.EntryCaller
+
+func (o ecsOrigin) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("file.name", o.File)
+	enc.AddInt("file.line", o.Line)
+	if o.Function != "" {
+		enc.AddString("function", o.Function)
+	}
+	return nil
+}
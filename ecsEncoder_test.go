@@ -0,0 +1,108 @@
+package slog
+
+import (
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func encodeJSONEntry(t *testing.T, enc zapcore.Encoder, ent zapcore.Entry, fields ...zapcore.Field) map[string]any {
+	t.Helper()
+	buf, err := enc.EncodeEntry(ent, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := make(map[string]any)
+	if err := json.Unmarshal(buf.Bytes(), &m); err != nil {
+		t.Fatal(err, buf.String())
+	}
+	return m
+}
+
+func TestECSEncoder(t *testing.T) {
+	enc := NewECSEncoder(zap.NewProductionEncoderConfig())
+	enc.AddString("handler", "UpdateUserName")
+	ent := zapcore.Entry{
+		Level:      zapcore.ErrorLevel,
+		Time:       time.Date(2024, 1, 2, 3, 4, 5, 6e6, time.FixedZone("X", 3600)),
+		LoggerName: "app",
+		Caller:     zapcore.NewEntryCaller(0, "/src/app/main.go", 12, true),
+		Message:    "query failed",
+	}
+	m := encodeJSONEntry(t, enc, ent, zap.Error(errors.New("boom")), zap.Int("n", 1))
+
+	expected := map[string]any{
+		"@timestamp":    "2024-01-02T02:04:05.006Z",
+		"log.level":     "error",
+		"log.logger":    "app",
+		"message":       "query failed",
+		"ecs.version":   ECSVersion,
+		"handler":       "UpdateUserName",
+		"error.message": "boom",
+		"error.type":    "*errors.errorString",
+		"n":             float64(1),
+	}
+	for k, v := range expected {
+		if m[k] != v {
+			t.Fatalf("NewECSEncoder does not work as expected. key: %s, value: %v", k, m[k])
+		}
+	}
+	origin, _ := m["log.origin"].(map[string]any)
+	if origin["file.name"] != "/src/app/main.go" || origin["file.line"] != float64(12) {
+		t.Fatalf("log.origin is not encoded as expected: %v", m["log.origin"])
+	}
+	if _, ok := m["error"]; ok {
+		t.Fatal("the error should be flattened into error.*")
+	}
+}
+
+func TestECSEncoder_StackTrace(t *testing.T) {
+	enc := NewECSEncoder(zap.NewProductionEncoderConfig())
+	ent := zapcore.Entry{Level: zapcore.ErrorLevel, Message: "failed", Stack: "captured"}
+	for _, c := range []struct {
+		err   error
+		stack string
+	}{
+		{err: errors.New("boom"), stack: "captured"},
+		{err: stackError{}, stack: "main.main()\n\tmain.go:10"},
+	} {
+		buf, err := enc.EncodeEntry(ent, []zapcore.Field{zap.Error(c.err)})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := strings.Count(buf.String(), `"error.stack_trace"`); n != 1 {
+			t.Fatal("error.stack_trace should be written once. output: " + buf.String())
+		}
+		if m := encodeJSONEntry(t, enc, ent, zap.Error(c.err)); m["error.stack_trace"] != c.stack {
+			t.Fatalf("error.stack_trace is not encoded as expected: %v", m["error.stack_trace"])
+		}
+	}
+}
+
+func TestConfig_ECS(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	cfg := NewProductionConfig()
+	cfg.Encoding = EncodingECS
+	cfg.OutputPaths = []string{path}
+	logger := cfg.MustBuild()
+	logger.Errorw("query failed", "err", errors.New("boom"))
+	_ = logger.FlushLogger()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := make(map[string]any)
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["log.level"] != "error" || m["message"] != "query failed" || m["error.message"] != "boom" {
+		t.Fatal("Config does not work as expected with the ecs encoding. output: " + string(data))
+	}
+}
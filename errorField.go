@@ -0,0 +1,198 @@
+package slog
+
+import (
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"reflect"
+	"strings"
+)
+
+const maxErrorDepth = 16
+
+// errorObject renders an error as a structured field with its message, its type name,
+// its causes and, if available, its stack trace. The stack trace is rendered only by
+// the object at the head of a chain of errors, i.e. the top one and the causes of an
+// error wrapping multiple errors.
+type errorObject struct {
+	err   error
+	depth int
+	head  bool
+}
+
+func errorField(key string, err error) zap.Field {
+	return zap.Object(key, errorObject{err: err, head: true})
+}
+
+func isNilError(err error) bool {
+	v := reflect.ValueOf(err)
+	switch v.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan, reflect.Interface:
+		return v.IsNil()
+	}
+	return false
+}
+
+func (eo errorObject) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	if isNilError(eo.err) {
+		enc.AddString("message", "<nil>")
+		enc.AddString("type", fmt.Sprintf("%T", eo.err))
+		return nil
+	}
+
+	enc.AddString("message", eo.err.Error())
+	enc.AddString("type", fmt.Sprintf("%T", eo.err))
+	if eo.head {
+		if stack := errorStack(eo.err); stack != "" {
+			enc.AddString("stack", stack)
+		}
+	}
+
+	if eo.depth >= maxErrorDepth {
+		return nil
+	}
+	var causes errorArray
+	switch x := eo.err.(type) {
+	case interface{ Unwrap() []error }:
+		causes = errorArray{errs: x.Unwrap(), head: true}
+	case interface{ Unwrap() error }:
+		if cause := x.Unwrap(); cause != nil {
+			causes = errorArray{errs: []error{cause}}
+		}
+	}
+	if len(causes.errs) > 0 {
+		causes.depth = eo.depth + 1
+		return enc.AddArray("causes", causes)
+	}
+	return nil
+}
+
+// errorStack returns the stack trace of the innermost error carrying one in the chain
+// of errors unwrapped from err by Unwrap() error, so that a stack trace shared by the
+// errors wrapping it is rendered only once.
+func errorStack(err error) string {
+	var stack string
+	for depth := 0; err != nil && depth <= maxErrorDepth; depth++ {
+		if s := stackTrace(err); s != "" {
+			stack = s
+		}
+		x, ok := err.(interface{ Unwrap() error })
+		if !ok {
+			break
+		}
+		err = x.Unwrap()
+	}
+	return stack
+}
+
+// stackTrace returns the stack trace carried by err, if err has a StackTrace method
+// like the errors of github.com/pkg/errors. The result of the method is printed with
+// %+v.
+func stackTrace(err error) string {
+	if isNilError(err) {
+		return ""
+	}
+	m := reflect.ValueOf(err).MethodByName("StackTrace")
+	if !m.IsValid() || m.Type().NumIn() != 0 || m.Type().NumOut() != 1 {
+		return ""
+	}
+	return strings.TrimLeft(fmt.Sprintf("%+v", m.Call(nil)[0].Interface()), "\n")
+}
+
+// fieldError returns the error held by a field created by zap.Error, zap.NamedError
+// or errorField.
+func fieldError(f zapcore.Field) (error, bool) {
+	switch f.Type {
+	case zapcore.ErrorType:
+		err, ok := f.Interface.(error)
+		return err, ok
+	case zapcore.ObjectMarshalerType:
+		if eo, ok := f.Interface.(errorObject); ok {
+			return eo.err, true
+		}
+	}
+	return nil, false
+}
+
+// flattenFirstError replaces the first error among fields with string fields holding
+// its message, type name and stack trace, under the keys used by a schema such as ECS.
+func flattenFirstError(fields []zapcore.Field, messageKey, typeKey, stackKey string) []zapcore.Field {
+	for i, f := range fields {
+		err, ok := fieldError(f)
+		if !ok || isNilError(err) {
+			continue
+		}
+		ret := make([]zapcore.Field, 0, len(fields)+2)
+		ret = append(ret, fields[:i]...)
+		ret = append(ret, zap.String(messageKey, err.Error()), zap.String(typeKey, fmt.Sprintf("%T", err)))
+		if stack := errorStack(err); stack != "" {
+			ret = append(ret, zap.String(stackKey, stack))
+		}
+		return append(ret, fields[i+1:]...)
+	}
+	return fields
+}
+
+type errorArray struct {
+	errs  []error
+	depth int
+	head  bool
+}
+
+func (ea errorArray) MarshalLogArray(enc zapcore.ArrayEncoder) error {
+	for _, err := range ea.errs {
+		if err == nil {
+			continue
+		}
+		if err := enc.AppendObject(errorObject{err: err, depth: ea.depth, head: ea.head}); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderErrors replaces the errors in keyVals with structured fields. The first error
+// without a key is keyed "error", as zap.SugaredLogger does. Malformed pairs are left
+// for zap to report.
+func renderErrors(keyVals []any) []any {
+	var ret []any
+	seenError := false
+	for i := 0; i < len(keyVals); {
+		var field any
+		n := 1
+		switch x := keyVals[i].(type) {
+		case zap.Field:
+		case error:
+			if !seenError {
+				seenError = true
+				field = errorField("error", x)
+			}
+		case string:
+			if i+1 < len(keyVals) {
+				n = 2
+				if err, ok := keyVals[i+1].(error); ok {
+					field = errorField(x, err)
+				}
+			}
+		default:
+			if i+1 < len(keyVals) {
+				n = 2
+			}
+		}
+
+		if field != nil && ret == nil {
+			ret = make([]any, 0, len(keyVals))
+			ret = append(ret, keyVals[:i]...)
+		}
+		if field != nil {
+			ret = append(ret, field)
+		} else if ret != nil {
+			ret = append(ret, keyVals[i:i+n]...)
+		}
+		i += n
+	}
+	if ret == nil {
+		return keyVals
+	}
+	return ret
+}
@@ -0,0 +1,116 @@
+package slog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"strings"
+	"testing"
+)
+
+type stackError struct{}
+
+func (stackError) Error() string { return "with stack" }
+
+func (stackError) StackTrace() string { return "\nmain.main()\n\tmain.go:10" }
+
+func (e stackError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		_, _ = io.WriteString(s, e.Error()+e.StackTrace())
+		return
+	}
+	_, _ = io.WriteString(s, e.Error())
+}
+
+type verboseError struct{}
+
+func (verboseError) Error() string { return "verbose" }
+
+func (e verboseError) Format(s fmt.State, verb rune) {
+	if verb == 'v' && s.Flag('+') {
+		_, _ = io.WriteString(s, "verbose: more details")
+		return
+	}
+	_, _ = io.WriteString(s, e.Error())
+}
+
+type ptrError struct{}
+
+func (*ptrError) Error() string { return "ptr" }
+
+// joinedError mimics what errors.Join returns, which needs Go 1.20.
+type joinedError []error
+
+func (e joinedError) Error() string {
+	var msgs []string
+	for _, err := range e {
+		msgs = append(msgs, err.Error())
+	}
+	return strings.Join(msgs, "\n")
+}
+
+func (e joinedError) Unwrap() []error { return e }
+
+func TestScavenger_ErrorFields(t *testing.T) {
+	sc := NewScavenger(WithStructuredErrors())
+	wrapped := fmt.Errorf("query failed: %w", io.EOF)
+	sc.Errorw("1", "err", wrapped)
+	sc.Errorw("2", joinedError{stackError{}, errors.New("b")})
+	sc.NewLoggerWith("cause", (*ptrError)(nil)).Info("3")
+	sc.Errorw("4", "err", fmt.Errorf("outer: %w", fmt.Errorf("inner: %w", stackError{})))
+	sc.Errorw("5", "err", verboseError{})
+
+	dump := `ERROR	1	{"err": {"message": "query failed: EOF", "type": "*fmt.wrapError", "causes": [{"message": "EOF", "type": "*errors.errorString"}]}}
+ERROR	2	{"error": {"message": "with stack\nb", "type": "slog.joinedError", "causes": [{"message": "with stack", "type": "slog.stackError", "stack": "main.main()\n\tmain.go:10"}, {"message": "b", "type": "*errors.errorString"}]}}
+INFO	3	{"cause": {"message": "<nil>", "type": "*slog.ptrError"}}
+ERROR	4	{"err": {"message": "outer: inner: with stack", "type": "*fmt.wrapError", "stack": "main.main()\n\tmain.go:10", "causes": [{"message": "inner: with stack", "type": "*fmt.wrapError", "causes": [{"message": "with stack", "type": "slog.stackError"}]}]}}
+ERROR	5	{"err": {"message": "verbose", "type": "slog.verboseError"}}
+`
+	if sc.Dump() != dump {
+		t.Fatal("something is wrong with error fields: " + sc.Dump())
+	}
+
+	sc = NewScavenger()
+	sc.NewLoggerWith("foo", 1).Errorw("1", "err", wrapped, errors.New("e"))
+	if sc.Dump() != "ERROR\t1\t{\"foo\": 1, \"err\": \"query failed: EOF\", \"error\": \"e\"}\n" {
+		t.Fatal("errors should be rendered as their messages by default: " + sc.Dump())
+	}
+}
+
+func TestZapLogger_ErrorFields(t *testing.T) {
+	var buf bytes.Buffer
+	enc := zapcore.NewJSONEncoder(zapcore.EncoderConfig{MessageKey: "msg"})
+	zsl := zap.New(zapcore.NewCore(enc, zapcore.AddSync(&buf), zapcore.DebugLevel)).Sugar()
+	zl := NewZapLogger(zsl, ZapLoggerStructuredErrors())
+	zl.NewLoggerWith("foo", 1).Errorw("failed", "err", fmt.Errorf("x: %w", io.EOF), "bar", 2)
+
+	expected := `{"msg":"failed","foo":1,"err":{"message":"x: EOF","type":"*fmt.wrapError","causes":[{"message":"EOF","type":"*errors.errorString"}]},"bar":2}` + "\n"
+	if buf.String() != expected {
+		t.Fatal("something is wrong with error fields: " + buf.String())
+	}
+
+	buf.Reset()
+	NewZapLogger(zsl).NewLoggerWith("foo", 1).Errorw("failed", "err", io.EOF)
+	if buf.String() != `{"msg":"failed","foo":1,"err":"EOF"}`+"\n" {
+		t.Fatal("errors should be rendered as their messages by default: " + buf.String())
+	}
+}
+
+func TestRenderErrors(t *testing.T) {
+	kvs := []any{"foo", 1, zap.Int("bar", 2)}
+	if ret := renderErrors(kvs); &ret[0] != &kvs[0] {
+		t.Fatal("renderErrors should not copy keyVals without errors")
+	}
+
+	e1, e2 := errors.New("e1"), errors.New("e2")
+	ret := renderErrors([]any{e1, "foo", 1, e2, 100, e1, "dangling"})
+	if len(ret) != 7 || ret[3] != e2 || ret[4] != 100 || ret[5] != e1 || ret[6] != "dangling" {
+		t.Fatal("renderErrors does not work as expected")
+	}
+	if f, ok := ret[0].(zap.Field); !ok || f.Key != "error" {
+		t.Fatal("renderErrors does not work as expected")
+	}
+}
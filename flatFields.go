@@ -0,0 +1,328 @@
+package slog
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"go.uber.org/zap/zapcore"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	_ zapcore.ObjectEncoder         = &fieldCollector{}
+	_ zapcore.PrimitiveArrayEncoder = &primitiveCollector{}
+)
+
+type valueKind int
+
+const (
+	kindString valueKind = iota
+	kindNumber
+	kindBool
+	kindTime
+	kindDuration
+	kindNull
+)
+
+// flatField is a field whose nested objects and arrays have been flattened into
+// dotted keys, e.g. "user.name" or "tags.0".
+type flatField struct {
+	key   string
+	value string
+	kind  valueKind
+}
+
+// fieldCollector is an ObjectEncoder that flattens everything added to it into a
+// list of flatFields. It backs the encoders that cannot represent nesting.
+type fieldCollector struct {
+	cfg    *zapcore.EncoderConfig
+	prefix string
+	fields []flatField
+}
+
+func (fc *fieldCollector) clone() *fieldCollector {
+	return &fieldCollector{
+		cfg:    fc.cfg,
+		prefix: fc.prefix,
+		fields: append([]flatField(nil), fc.fields...),
+	}
+}
+
+func (fc *fieldCollector) add(key, value string, kind valueKind) {
+	fc.fields = append(fc.fields, flatField{key: fc.prefix + key, value: value, kind: kind})
+}
+
+func (fc *fieldCollector) nested(key string) *fieldCollector {
+	return &fieldCollector{cfg: fc.cfg, prefix: fc.prefix + key + "."}
+}
+
+func (fc *fieldCollector) adopt(sub *fieldCollector) {
+	fc.fields = append(fc.fields, sub.fields...)
+}
+
+func (fc *fieldCollector) AddArray(key string, marshaler zapcore.ArrayMarshaler) error {
+	ac := &arrayCollector{fc: fc.nested(key)}
+	err := marshaler.MarshalLogArray(ac)
+	fc.adopt(ac.fc)
+	return err
+}
+
+func (fc *fieldCollector) AddObject(key string, marshaler zapcore.ObjectMarshaler) error {
+	sub := fc.nested(key)
+	err := marshaler.MarshalLogObject(sub)
+	fc.adopt(sub)
+	return err
+}
+
+func (fc *fieldCollector) AddBinary(key string, value []byte) {
+	fc.add(key, base64.StdEncoding.EncodeToString(value), kindString)
+}
+
+func (fc *fieldCollector) AddByteString(key string, value []byte) {
+	fc.add(key, string(value), kindString)
+}
+
+func (fc *fieldCollector) AddBool(key string, value bool) {
+	fc.add(key, strconv.FormatBool(value), kindBool)
+}
+
+func (fc *fieldCollector) AddComplex128(key string, value complex128) {
+	fc.add(key, strconv.FormatComplex(value, 'g', -1, 128), kindNumber)
+}
+
+func (fc *fieldCollector) AddComplex64(key string, value complex64) {
+	fc.add(key, strconv.FormatComplex(complex128(value), 'g', -1, 64), kindNumber)
+}
+
+func (fc *fieldCollector) AddDuration(key string, value time.Duration) {
+	if fc.cfg != nil && fc.cfg.EncodeDuration != nil {
+		var pc primitiveCollector
+		fc.cfg.EncodeDuration(value, &pc)
+		if s, ok := pc.value(); ok {
+			fc.add(key, s, kindDuration)
+			return
+		}
+	}
+	fc.add(key, value.String(), kindDuration)
+}
+
+func (fc *fieldCollector) AddFloat64(key string, value float64) {
+	fc.add(key, strconv.FormatFloat(value, 'g', -1, 64), kindNumber)
+}
+
+func (fc *fieldCollector) AddFloat32(key string, value float32) {
+	fc.add(key, strconv.FormatFloat(float64(value), 'g', -1, 32), kindNumber)
+}
+
+func (fc *fieldCollector) AddInt(key string, value int) {
+	fc.AddInt64(key, int64(value))
+}
+
+func (fc *fieldCollector) AddInt64(key string, value int64) {
+	fc.add(key, strconv.FormatInt(value, 10), kindNumber)
+}
+
+func (fc *fieldCollector) AddInt32(key string, value int32) {
+	fc.AddInt64(key, int64(value))
+}
+
+func (fc *fieldCollector) AddInt16(key string, value int16) {
+	fc.AddInt64(key, int64(value))
+}
+
+func (fc *fieldCollector) AddInt8(key string, value int8) {
+	fc.AddInt64(key, int64(value))
+}
+
+func (fc *fieldCollector) AddString(key, value string) {
+	fc.add(key, value, kindString)
+}
+
+func (fc *fieldCollector) AddTime(key string, value time.Time) {
+	if fc.cfg != nil && fc.cfg.EncodeTime != nil {
+		var pc primitiveCollector
+		fc.cfg.EncodeTime(value, &pc)
+		if s, ok := pc.value(); ok {
+			fc.add(key, s, kindTime)
+			return
+		}
+	}
+	fc.add(key, value.Format(time.RFC3339Nano), kindTime)
+}
+
+func (fc *fieldCollector) AddUint(key string, value uint) {
+	fc.AddUint64(key, uint64(value))
+}
+
+func (fc *fieldCollector) AddUint64(key string, value uint64) {
+	fc.add(key, strconv.FormatUint(value, 10), kindNumber)
+}
+
+func (fc *fieldCollector) AddUint32(key string, value uint32) {
+	fc.AddUint64(key, uint64(value))
+}
+
+func (fc *fieldCollector) AddUint16(key string, value uint16) {
+	fc.AddUint64(key, uint64(value))
+}
+
+func (fc *fieldCollector) AddUint8(key string, value uint8) {
+	fc.AddUint64(key, uint64(value))
+}
+
+func (fc *fieldCollector) AddUintptr(key string, value uintptr) {
+	fc.AddUint64(key, uint64(value))
+}
+
+// AddReflected round-trips the value through encoding/json so that maps and structs
+// are flattened like any other object. Numbers are kept as they are encoded, so that
+// large integers do not lose precision.
+func (fc *fieldCollector) AddReflected(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return err
+	}
+	fc.addJSON(key, v)
+	return nil
+}
+
+func (fc *fieldCollector) addJSON(key string, v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		fc.add(key, "null", kindNull)
+	case bool:
+		fc.AddBool(key, v)
+	case json.Number:
+		fc.add(key, v.String(), kindNumber)
+	case string:
+		fc.AddString(key, v)
+	case []interface{}:
+		sub := fc.nested(key)
+		for i, e := range v {
+			sub.addJSON(strconv.Itoa(i), e)
+		}
+		fc.adopt(sub)
+	case map[string]interface{}:
+		sub := fc.nested(key)
+		for _, k := range sortedKeys(v) {
+			sub.addJSON(k, v[k])
+		}
+		fc.adopt(sub)
+	default:
+		fc.add(key, fmt.Sprint(v), kindString)
+	}
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func (fc *fieldCollector) OpenNamespace(key string) {
+	fc.prefix += key + "."
+}
+
+// arrayCollector flattens array elements into keys named after their indexes.
+type arrayCollector struct {
+	fc *fieldCollector
+	n  int
+}
+
+func (ac *arrayCollector) next() string {
+	s := strconv.Itoa(ac.n)
+	ac.n++
+	return s
+}
+
+func (ac *arrayCollector) AppendArray(v zapcore.ArrayMarshaler) error {
+	return ac.fc.AddArray(ac.next(), v)
+}
+
+func (ac *arrayCollector) AppendObject(v zapcore.ObjectMarshaler) error {
+	return ac.fc.AddObject(ac.next(), v)
+}
+
+func (ac *arrayCollector) AppendReflected(v interface{}) error {
+	return ac.fc.AddReflected(ac.next(), v)
+}
+
+func (ac *arrayCollector) AppendBool(v bool)              { ac.fc.AddBool(ac.next(), v) }
+func (ac *arrayCollector) AppendByteString(v []byte)      { ac.fc.AddByteString(ac.next(), v) }
+func (ac *arrayCollector) AppendComplex128(v complex128)  { ac.fc.AddComplex128(ac.next(), v) }
+func (ac *arrayCollector) AppendComplex64(v complex64)    { ac.fc.AddComplex64(ac.next(), v) }
+func (ac *arrayCollector) AppendFloat64(v float64)        { ac.fc.AddFloat64(ac.next(), v) }
+func (ac *arrayCollector) AppendFloat32(v float32)        { ac.fc.AddFloat32(ac.next(), v) }
+func (ac *arrayCollector) AppendInt(v int)                { ac.fc.AddInt(ac.next(), v) }
+func (ac *arrayCollector) AppendInt64(v int64)            { ac.fc.AddInt64(ac.next(), v) }
+func (ac *arrayCollector) AppendInt32(v int32)            { ac.fc.AddInt32(ac.next(), v) }
+func (ac *arrayCollector) AppendInt16(v int16)            { ac.fc.AddInt16(ac.next(), v) }
+func (ac *arrayCollector) AppendInt8(v int8)              { ac.fc.AddInt8(ac.next(), v) }
+func (ac *arrayCollector) AppendString(v string)          { ac.fc.AddString(ac.next(), v) }
+func (ac *arrayCollector) AppendUint(v uint)              { ac.fc.AddUint(ac.next(), v) }
+func (ac *arrayCollector) AppendUint64(v uint64)          { ac.fc.AddUint64(ac.next(), v) }
+func (ac *arrayCollector) AppendUint32(v uint32)          { ac.fc.AddUint32(ac.next(), v) }
+func (ac *arrayCollector) AppendUint16(v uint16)          { ac.fc.AddUint16(ac.next(), v) }
+func (ac *arrayCollector) AppendUint8(v uint8)            { ac.fc.AddUint8(ac.next(), v) }
+func (ac *arrayCollector) AppendUintptr(v uintptr)        { ac.fc.AddUintptr(ac.next(), v) }
+func (ac *arrayCollector) AppendDuration(v time.Duration) { ac.fc.AddDuration(ac.next(), v) }
+func (ac *arrayCollector) AppendTime(v time.Time)         { ac.fc.AddTime(ac.next(), v) }
+
+// primitiveCollector captures what the EncodeTime, EncodeLevel and friends of an
+// EncoderConfig append, so that their output can be reused by non-JSON encoders.
+type primitiveCollector struct {
+	values []string
+}
+
+func (pc *primitiveCollector) value() (string, bool) {
+	if len(pc.values) == 0 {
+		return "", false
+	}
+	return strings.Join(pc.values, " "), true
+}
+
+func (pc *primitiveCollector) append(s string) {
+	pc.values = append(pc.values, s)
+}
+
+func (pc *primitiveCollector) AppendBool(v bool) { pc.append(strconv.FormatBool(v)) }
+func (pc *primitiveCollector) AppendByteString(v []byte) {
+	pc.append(string(v))
+}
+func (pc *primitiveCollector) AppendComplex128(v complex128) {
+	pc.append(strconv.FormatComplex(v, 'g', -1, 128))
+}
+func (pc *primitiveCollector) AppendComplex64(v complex64) {
+	pc.append(strconv.FormatComplex(complex128(v), 'g', -1, 64))
+}
+func (pc *primitiveCollector) AppendFloat64(v float64) {
+	pc.append(strconv.FormatFloat(v, 'g', -1, 64))
+}
+func (pc *primitiveCollector) AppendFloat32(v float32) {
+	pc.append(strconv.FormatFloat(float64(v), 'g', -1, 32))
+}
+func (pc *primitiveCollector) AppendInt(v int)         { pc.AppendInt64(int64(v)) }
+func (pc *primitiveCollector) AppendInt64(v int64)     { pc.append(strconv.FormatInt(v, 10)) }
+func (pc *primitiveCollector) AppendInt32(v int32)     { pc.AppendInt64(int64(v)) }
+func (pc *primitiveCollector) AppendInt16(v int16)     { pc.AppendInt64(int64(v)) }
+func (pc *primitiveCollector) AppendInt8(v int8)       { pc.AppendInt64(int64(v)) }
+func (pc *primitiveCollector) AppendString(v string)   { pc.append(v) }
+func (pc *primitiveCollector) AppendUint(v uint)       { pc.AppendUint64(uint64(v)) }
+func (pc *primitiveCollector) AppendUint64(v uint64)   { pc.append(strconv.FormatUint(v, 10)) }
+func (pc *primitiveCollector) AppendUint32(v uint32)   { pc.AppendUint64(uint64(v)) }
+func (pc *primitiveCollector) AppendUint16(v uint16)   { pc.AppendUint64(uint64(v)) }
+func (pc *primitiveCollector) AppendUint8(v uint8)     { pc.AppendUint64(uint64(v)) }
+func (pc *primitiveCollector) AppendUintptr(v uintptr) { pc.AppendUint64(uint64(v)) }
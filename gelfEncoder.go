@@ -0,0 +1,143 @@
+package slog
+
+import (
+	"encoding/json"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"strconv"
+	"strings"
+)
+
+const (
+	// EncodingGELF is the Config.Encoding value that selects the GELF 1.1 encoder.
+	EncodingGELF = "gelf"
+)
+
+var (
+	_ zapcore.Encoder = &gelfEncoder{}
+)
+
+type gelfEncoder struct {
+	*fieldCollector
+	host string
+}
+
+// NewGELFEncoder creates an encoder that writes GELF 1.1 messages for Graylog. The
+// message becomes short_message and the stack trace full_message. Levels are mapped
+// to syslog severities. Fields become additional fields, prefixed with an underscore
+// and flattened with dotted keys because GELF does not support nesting. Pair it with
+// a gelf+udp:// or gelf+tcp:// sink.
+func NewGELFEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	host, err := os.Hostname()
+	if err != nil || host == "" {
+		host = "localhost"
+	}
+	if cfg.SkipLineEnding {
+		cfg.LineEnding = ""
+	} else if cfg.LineEnding == "" {
+		cfg.LineEnding = zapcore.DefaultLineEnding
+	}
+	return &gelfEncoder{
+		fieldCollector: &fieldCollector{cfg: &cfg},
+		host:           host,
+	}
+}
+
+func (enc *gelfEncoder) Clone() zapcore.Encoder {
+	return &gelfEncoder{
+		fieldCollector: enc.fieldCollector.clone(),
+		host:           enc.host,
+	}
+}
+
+func (enc *gelfEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	fc := enc.fieldCollector.clone()
+	for i := range fields {
+		fields[i].AddTo(fc)
+	}
+
+	buf := _bufferPool.Get()
+	buf.AppendString(`{"version":"1.1","host":`)
+	appendJSONString(buf, enc.host)
+	buf.AppendString(`,"short_message":`)
+	appendJSONString(buf, ent.Message)
+	if ent.Stack != "" {
+		buf.AppendString(`,"full_message":`)
+		appendJSONString(buf, ent.Message+"\n"+ent.Stack)
+	}
+	buf.AppendString(`,"timestamp":`)
+	buf.AppendString(strconv.FormatFloat(float64(ent.Time.UnixMicro())/1e6, 'f', -1, 64))
+	buf.AppendString(`,"level":`)
+	buf.AppendInt(int64(syslogSeverity(ent.Level)))
+	if ent.LoggerName != "" {
+		buf.AppendString(`,"_logger":`)
+		appendJSONString(buf, ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		buf.AppendString(`,"_caller":`)
+		appendJSONString(buf, ent.Caller.TrimmedPath())
+	}
+	for _, f := range fc.fields {
+		buf.AppendString(`,"`)
+		buf.AppendString(gelfFieldName(f.key))
+		buf.AppendString(`":`)
+		if gelfNumeric(f) {
+			buf.AppendString(f.value)
+		} else {
+			appendJSONString(buf, f.value)
+		}
+	}
+	buf.AppendByte('}')
+	buf.AppendString(enc.cfg.LineEnding)
+	return buf, nil
+}
+
+// syslogSeverity maps a level to a syslog severity: debug (7), informational (6),
+// warning (4), error (3), critical (2) and alert (1).
+func syslogSeverity(level zapcore.Level) int {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return 7
+	case level == zapcore.InfoLevel:
+		return 6
+	case level == zapcore.WarnLevel:
+		return 4
+	case level == zapcore.ErrorLevel:
+		return 3
+	case level == zapcore.DPanicLevel || level == zapcore.PanicLevel:
+		return 2
+	default:
+		return 1
+	}
+}
+
+// gelfFieldName prefixes key with an underscore and replaces the characters that
+// GELF does not allow in field names. "_id" is reserved, so "id" becomes "_id_".
+func gelfFieldName(key string) string {
+	if key == "id" {
+		return "_id_"
+	}
+	return "_" + strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '_', r == '.', r == '-':
+			return r
+		default:
+			return '_'
+		}
+	}, key)
+}
+
+// gelfNumeric reports whether a field can be written as a JSON number. GELF only
+// supports strings and numbers, so bools, NaN and the like are written as strings.
+func gelfNumeric(f flatField) bool {
+	switch f.kind {
+	case kindNumber, kindDuration, kindTime:
+		if f.value == "" || f.value[0] != '-' && (f.value[0] < '0' || f.value[0] > '9') {
+			return false
+		}
+		return json.Valid([]byte(f.value))
+	default:
+		return false
+	}
+}
@@ -0,0 +1,52 @@
+package slog
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"math"
+	"os"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestGELFEncoder(t *testing.T) {
+	host, _ := os.Hostname()
+	enc := NewGELFEncoder(zap.NewProductionEncoderConfig())
+	enc.AddString("handler", "UpdateUserName")
+	ent := zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Time:    time.UnixMilli(1700000000123),
+		Caller:  zapcore.NewEntryCaller(0, "/src/app/main.go", 12, true),
+		Message: "slow query",
+		Stack:   "main.main()",
+	}
+	m := encodeJSONEntry(t, enc, ent,
+		zap.Int("id", 7),
+		zap.Bool("ok", true),
+		zap.Float64("nan", math.NaN()),
+		zap.Duration("elapsed", 1500*time.Millisecond),
+		zap.Object("user", logfmtUser{name: "tom", tags: []string{"x"}}),
+		zap.String("bad key", "v"))
+
+	expected := map[string]any{
+		"version":       "1.1",
+		"host":          host,
+		"short_message": "slow query",
+		"full_message":  "slow query\nmain.main()",
+		"timestamp":     1700000000.123,
+		"level":         float64(4),
+		"_caller":       "app/main.go:12",
+		"_handler":      "UpdateUserName",
+		"_id_":          float64(7),
+		"_ok":           "true",
+		"_nan":          "NaN",
+		"_elapsed":      1.5,
+		"_user.name":    "tom",
+		"_user.tags.0":  "x",
+		"_bad_key":      "v",
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Fatalf("NewGELFEncoder does not work as expected: %v", m)
+	}
+}
@@ -0,0 +1,206 @@
+package slog
+
+import (
+	"bytes"
+	"compress/gzip"
+	"compress/zlib"
+	"crypto/rand"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"net/url"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	defaultGELFChunkSize    = 1420
+	defaultGELFDialTimeout  = 5 * time.Second
+	defaultGELFWriteTimeout = 5 * time.Second
+
+	gelfChunkHeaderSize = 12
+	gelfMaxChunks       = 128
+)
+
+var (
+	_ zap.Sink = &gelfUDPSink{}
+	_ zap.Sink = &gelfTCPSink{}
+)
+
+// newGELFUDPSink creates a sink from a URL like
+// gelf+udp://graylog:12201?compress=gzip&chunk_size=1420. compress is one of none (the
+// default), gzip and zlib.
+func newGELFUDPSink(u *url.URL) (zap.Sink, error) {
+	q := u.Query()
+	chunkSize := defaultGELFChunkSize
+	if s := q.Get("chunk_size"); s != "" {
+		n, err := strconv.Atoi(s)
+		if err != nil || n <= gelfChunkHeaderSize {
+			return nil, fmt.Errorf("invalid chunk_size of the gelf+udp sink: %q", s)
+		}
+		chunkSize = n
+	}
+	compress := q.Get("compress")
+	switch compress {
+	case "", "none":
+		compress = ""
+	case "gzip", "zlib":
+	default:
+		return nil, fmt.Errorf("unknown compression of the gelf+udp sink: %q", compress)
+	}
+
+	conn, err := net.Dial("udp", u.Host)
+	if err != nil {
+		return nil, err
+	}
+	return &gelfUDPSink{conn: conn, chunkSize: chunkSize, compress: compress}, nil
+}
+
+type gelfUDPSink struct {
+	mu        sync.Mutex
+	conn      net.Conn
+	chunkSize int
+	compress  string
+}
+
+func (s *gelfUDPSink) Write(p []byte) (n int, err error) {
+	msg, err := s.encode(bytes.TrimRight(p, "\r\n"))
+	if err != nil {
+		return 0, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(msg) <= s.chunkSize {
+		if _, err := s.conn.Write(msg); err != nil {
+			return 0, err
+		}
+		return len(p), nil
+	}
+
+	size := s.chunkSize - gelfChunkHeaderSize
+	count := (len(msg) + size - 1) / size
+	if count > gelfMaxChunks {
+		return 0, fmt.Errorf("the GELF message is too large to be sent over UDP: %d bytes", len(msg))
+	}
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		return 0, err
+	}
+	chunk := make([]byte, 0, s.chunkSize)
+	for i := 0; i < count; i++ {
+		chunk = append(chunk[:0], 0x1e, 0x0f)
+		chunk = append(chunk, id[:]...)
+		chunk = append(chunk, byte(i), byte(count))
+		end := (i + 1) * size
+		if end > len(msg) {
+			end = len(msg)
+		}
+		chunk = append(chunk, msg[i*size:end]...)
+		if _, err := s.conn.Write(chunk); err != nil {
+			return 0, err
+		}
+	}
+	return len(p), nil
+}
+
+func (s *gelfUDPSink) encode(msg []byte) ([]byte, error) {
+	var w io.WriteCloser
+	var buf bytes.Buffer
+	switch s.compress {
+	case "gzip":
+		w = gzip.NewWriter(&buf)
+	case "zlib":
+		w = zlib.NewWriter(&buf)
+	default:
+		return msg, nil
+	}
+	if _, err := w.Write(msg); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *gelfUDPSink) Sync() error {
+	return nil
+}
+
+func (s *gelfUDPSink) Close() error {
+	return s.conn.Close()
+}
+
+// newGELFTCPSink creates a sink from a URL like
+// gelf+tcp://graylog:12201?dial_timeout=5s&write_timeout=5s. The connection is
+// established lazily and re-established when a write fails.
+func newGELFTCPSink(u *url.URL) (zap.Sink, error) {
+	q := u.Query()
+	s := &gelfTCPSink{
+		addr:         u.Host,
+		dialTimeout:  defaultGELFDialTimeout,
+		writeTimeout: defaultGELFWriteTimeout,
+	}
+	for name, d := range map[string]*time.Duration{"dial_timeout": &s.dialTimeout, "write_timeout": &s.writeTimeout} {
+		if v := q.Get(name); v != "" {
+			t, err := time.ParseDuration(v)
+			if err != nil || t <= 0 {
+				return nil, fmt.Errorf("invalid %s of the gelf+tcp sink: %q", name, v)
+			}
+			*d = t
+		}
+	}
+	return s, nil
+}
+
+type gelfTCPSink struct {
+	mu           sync.Mutex
+	addr         string
+	conn         net.Conn
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+}
+
+// Write sends a message terminated by a null byte, as GELF over TCP requires. If the
+// connection turns out to be broken, Write reconnects and tries once more.
+func (s *gelfTCPSink) Write(p []byte) (n int, err error) {
+	trimmed := bytes.TrimRight(p, "\r\n")
+	msg := make([]byte, len(trimmed)+1)
+	copy(msg, trimmed)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.conn == nil {
+			if s.conn, err = net.DialTimeout("tcp", s.addr, s.dialTimeout); err != nil {
+				s.conn = nil
+				return 0, err
+			}
+		}
+		_ = s.conn.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+		if _, err = s.conn.Write(msg); err == nil {
+			return len(p), nil
+		}
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+	return 0, err
+}
+
+func (s *gelfTCPSink) Sync() error {
+	return nil
+}
+
+func (s *gelfTCPSink) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
@@ -0,0 +1,112 @@
+package slog
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newGELFLogger(t *testing.T, url string) *ZapLogger {
+	t.Helper()
+	cfg := NewProductionConfig()
+	cfg.Encoding = EncodingGELF
+	cfg.OutputPaths = []string{url}
+	l, err := cfg.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func TestGELFUDPSink(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+
+	l := newGELFLogger(t, "gelf+udp://"+pc.LocalAddr().String()+"?compress=gzip&chunk_size=100")
+	long := strings.Repeat("0123456789", 50)
+	l.Infow("hello", "payload", long)
+
+	chunks := make(map[byte][]byte)
+	var count byte
+	buf := make([]byte, 65536)
+	for count == 0 || len(chunks) < int(count) {
+		n, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n > 100 || buf[0] != 0x1e || buf[1] != 0x0f {
+			t.Fatal("the message is not chunked as expected")
+		}
+		chunks[buf[10]] = append([]byte(nil), buf[12:n]...)
+		count = buf[11]
+	}
+	var msg []byte
+	for i := byte(0); i < count; i++ {
+		msg = append(msg, chunks[i]...)
+	}
+	r, err := gzip.NewReader(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := io.ReadAll(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	m := make(map[string]any)
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	if m["short_message"] != "hello" || m["_payload"] != long {
+		t.Fatal("gelf+udp does not work as expected. message: " + string(data))
+	}
+}
+
+func TestGELFTCPSink(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	messages := make(chan string, 10)
+	accept := func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		r := bufio.NewReader(conn)
+		msg, err := r.ReadString(0)
+		if err == nil {
+			messages <- strings.TrimSuffix(msg, "\x00")
+		}
+		_ = conn.Close()
+	}
+
+	l := newGELFLogger(t, "gelf+tcp://"+ln.Addr().String()+"?write_timeout=1s")
+	for i := 0; i < 2; i++ {
+		go accept()
+		var msg string
+		for deadline := time.Now().Add(5 * time.Second); msg == "" && time.Now().Before(deadline); {
+			// The first writes after the server closed the connection may succeed, so
+			// keep writing until the sink reconnects.
+			l.Infow("hello", "i", i)
+			select {
+			case msg = <-messages:
+			case <-time.After(50 * time.Millisecond):
+			}
+		}
+		if !strings.Contains(msg, `"short_message":"hello"`) || !strings.Contains(msg, `"_i":`+string(rune('0'+i))) {
+			t.Fatalf("gelf+tcp does not work as expected. i: %d, message: %s", i, msg)
+		}
+	}
+}
@@ -1,13 +1,13 @@
 module github.com/edwingeng/slog
 
-go 1.18
+go 1.19
 
 require (
 	go.uber.org/zap v1.27.0
-	golang.org/x/term v0.20.0
+	golang.org/x/term v0.25.0
 )
 
 require (
 	go.uber.org/multierr v1.11.0 // indirect
-	golang.org/x/sys v0.20.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
 )
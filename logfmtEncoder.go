@@ -0,0 +1,172 @@
+package slog
+
+import (
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// EncodingLogfmt is the Config.Encoding value that selects the logfmt encoder.
+	EncodingLogfmt = "logfmt"
+)
+
+var (
+	_ zapcore.Encoder = &logfmtEncoder{}
+)
+
+var (
+	_bufferPool = buffer.NewPool()
+)
+
+type logfmtEncoder struct {
+	*fieldCollector
+}
+
+// NewLogfmtEncoder creates an encoder that writes each entry as a line of key=value
+// pairs. Nested objects and arrays are flattened with dotted keys, e.g. user.name=x
+// and tags.0=y. It is what Config.Build uses when Encoding is "logfmt".
+func NewLogfmtEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	return newLogfmtEncoder(cfg)
+}
+
+func newLogfmtEncoder(cfg zapcore.EncoderConfig) *logfmtEncoder {
+	if cfg.SkipLineEnding {
+		cfg.LineEnding = ""
+	} else if cfg.LineEnding == "" {
+		cfg.LineEnding = zapcore.DefaultLineEnding
+	}
+	return &logfmtEncoder{
+		fieldCollector: &fieldCollector{cfg: &cfg},
+	}
+}
+
+func (enc *logfmtEncoder) Clone() zapcore.Encoder {
+	return &logfmtEncoder{
+		fieldCollector: enc.fieldCollector.clone(),
+	}
+}
+
+func (enc *logfmtEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	fc := enc.fieldCollector.clone()
+	for i := range fields {
+		fields[i].AddTo(fc)
+	}
+
+	buf := _bufferPool.Get()
+	for _, h := range entryHeader(enc.cfg, ent) {
+		appendLogfmtPair(buf, h.key, h.value)
+	}
+	for _, f := range fc.fields {
+		appendLogfmtPair(buf, f.key, f.value)
+	}
+	if ent.Stack != "" && enc.cfg.StacktraceKey != "" {
+		appendLogfmtPair(buf, enc.cfg.StacktraceKey, ent.Stack)
+	}
+	buf.AppendString(enc.cfg.LineEnding)
+	return buf, nil
+}
+
+// entryHeader renders the fixed part of an entry (level, time, logger name, caller,
+// function and message) with the encoders and keys of cfg, in the order used by
+// zap's JSON encoder. Empty keys are skipped.
+func entryHeader(cfg *zapcore.EncoderConfig, ent zapcore.Entry) []flatField {
+	var header []flatField
+	encode := func(key string, fn func(enc zapcore.PrimitiveArrayEncoder), fallback string) {
+		var pc primitiveCollector
+		if fn != nil {
+			fn(&pc)
+		}
+		s, ok := pc.value()
+		if !ok {
+			s = fallback
+		}
+		header = append(header, flatField{key: key, value: s})
+	}
+
+	if cfg.LevelKey != "" {
+		var fn func(zapcore.PrimitiveArrayEncoder)
+		if cfg.EncodeLevel != nil {
+			fn = func(enc zapcore.PrimitiveArrayEncoder) { cfg.EncodeLevel(ent.Level, enc) }
+		}
+		encode(cfg.LevelKey, fn, ent.Level.String())
+	}
+	if cfg.TimeKey != "" && !ent.Time.IsZero() {
+		var fn func(zapcore.PrimitiveArrayEncoder)
+		if cfg.EncodeTime != nil {
+			fn = func(enc zapcore.PrimitiveArrayEncoder) { cfg.EncodeTime(ent.Time, enc) }
+		}
+		encode(cfg.TimeKey, fn, strconv.FormatInt(ent.Time.UnixNano(), 10))
+		header[len(header)-1].kind = kindTime
+	}
+	if ent.LoggerName != "" && cfg.NameKey != "" {
+		var fn func(zapcore.PrimitiveArrayEncoder)
+		if cfg.EncodeName != nil {
+			fn = func(enc zapcore.PrimitiveArrayEncoder) { cfg.EncodeName(ent.LoggerName, enc) }
+		}
+		encode(cfg.NameKey, fn, ent.LoggerName)
+	}
+	if ent.Caller.Defined {
+		if cfg.CallerKey != "" {
+			var fn func(zapcore.PrimitiveArrayEncoder)
+			if cfg.EncodeCaller != nil {
+				fn = func(enc zapcore.PrimitiveArrayEncoder) { cfg.EncodeCaller(ent.Caller, enc) }
+			}
+			encode(cfg.CallerKey, fn, ent.Caller.TrimmedPath())
+		}
+		if cfg.FunctionKey != "" {
+			header = append(header, flatField{key: cfg.FunctionKey, value: ent.Caller.Function})
+		}
+	}
+	if cfg.MessageKey != "" {
+		header = append(header, flatField{key: cfg.MessageKey, value: ent.Message})
+	}
+	return header
+}
+
+func appendLogfmtPair(buf *buffer.Buffer, key, value string) {
+	if buf.Len() > 0 {
+		buf.AppendByte(' ')
+	}
+	buf.AppendString(logfmtKey(key))
+	buf.AppendByte('=')
+	if logfmtNeedsQuote(value) {
+		buf.AppendString(strconv.Quote(value))
+	} else {
+		buf.AppendString(value)
+	}
+}
+
+// logfmtKey replaces the characters that cannot appear in an unquoted logfmt key.
+func logfmtKey(key string) string {
+	if key == "" {
+		return "_"
+	}
+	if strings.IndexFunc(key, invalidLogfmtKeyRune) < 0 {
+		return key
+	}
+	return strings.Map(func(r rune) rune {
+		if invalidLogfmtKeyRune(r) {
+			return '_'
+		}
+		return r
+	}, key)
+}
+
+func invalidLogfmtKeyRune(r rune) bool {
+	return r <= ' ' || r == '=' || r == '"' || r == utf8.RuneError || r == 0x7f
+}
+
+func logfmtNeedsQuote(value string) bool {
+	if value == "" {
+		return true
+	}
+	for _, r := range value {
+		if r <= ' ' || r == '=' || r == '"' || r == '\\' || r == 0x7f || r == utf8.RuneError || !strconv.IsPrint(r) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,121 @@
+package slog
+
+import (
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+type logfmtUser struct {
+	name string
+	tags []string
+}
+
+func (u logfmtUser) MarshalLogObject(enc zapcore.ObjectEncoder) error {
+	enc.AddString("name", u.name)
+	return enc.AddArray("tags", zapcore.ArrayMarshalerFunc(func(enc zapcore.ArrayEncoder) error {
+		for _, tag := range u.tags {
+			enc.AppendString(tag)
+		}
+		return nil
+	}))
+}
+
+func TestLogfmtEncoder(t *testing.T) {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.EncodeTime = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(t.Format("15:04:05"))
+	}
+	enc := NewLogfmtEncoder(cfg)
+	enc.AddString("handler", "UpdateUserName")
+
+	ent := zapcore.Entry{
+		Level:   zapcore.WarnLevel,
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Message: `say "hi"`,
+	}
+	fields := []zapcore.Field{
+		zap.Int("n", 7),
+		zap.String("empty", ""),
+		zap.String("multi", "a\nb"),
+		zap.Bool("ok", true),
+		zap.Duration("elapsed", time.Second),
+		zap.Object("user", logfmtUser{name: "tom", tags: []string{"x", "y z"}}),
+		zap.Any("m", map[string]any{"b": 1, "a": []int{2}, "c": int64(1234567890123456789)}),
+		zap.String("bad key=", "v"),
+	}
+	buf, err := enc.EncodeEntry(ent, fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected = `level=warn ts=03:04:05 msg="say \"hi\"" handler=UpdateUserName n=7 empty="" multi="a\nb" ok=true ` +
+		`elapsed=1 user.name=tom user.tags.0=x user.tags.1="y z" m.a.0=2 m.b=1 m.c=1234567890123456789 bad_key_=v` + "\n"
+	if buf.String() != expected {
+		t.Fatal("NewLogfmtEncoder does not work as expected. output: " + buf.String())
+	}
+
+	buf, err = enc.EncodeEntry(ent, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != `level=warn ts=03:04:05 msg="say \"hi\"" handler=UpdateUserName`+"\n" {
+		t.Fatal("EncodeEntry should not modify the context fields of the encoder. output: " + buf.String())
+	}
+}
+
+func TestLogfmtEncoder_Namespace(t *testing.T) {
+	cfg := zap.NewProductionEncoderConfig()
+	cfg.TimeKey = ""
+	cfg.SkipLineEnding = true
+	enc := NewLogfmtEncoder(cfg)
+	buf, err := enc.EncodeEntry(zapcore.Entry{Level: zapcore.InfoLevel, Message: "hello"}, []zapcore.Field{
+		zap.Namespace("req"),
+		zap.String("id", "r1"),
+		zap.Error(fmt.Errorf("wrapped: %w", errors.New("boom"))),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if buf.String() != `level=info msg=hello req.id=r1 req.error="wrapped: boom"` {
+		t.Fatal("OpenNamespace does not work as expected. output: " + buf.String())
+	}
+}
+
+func TestConfig_Logfmt(t *testing.T) {
+	for _, cfg := range []*Config{NewDevelopmentConfigWith("2006-01-02"), NewProductionConfig()} {
+		path := filepath.Join(t.TempDir(), "log.txt")
+		cfg.Encoding = EncodingLogfmt
+		cfg.EncoderConfig.EncodeLevel = zapcore.LowercaseLevelEncoder
+		cfg.OutputPaths = []string{path}
+		cfg.StructuredErrors = true
+		logger := cfg.MustBuild()
+		logger.Warnw("disk almost full", "free", "1 GiB", "err", errors.New("oops"))
+		_ = logger.FlushLogger()
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		line := string(data)
+		ec := cfg.EncoderConfig
+		if !strings.HasPrefix(line, ec.LevelKey+"=warn ") ||
+			!strings.Contains(line, " "+ec.MessageKey+`="disk almost full" free="1 GiB" err.message=oops err.type=*errors.errorString`) {
+			t.Fatal("Config does not work as expected with the logfmt encoding. output: " + line)
+		}
+		if cfg.Development {
+			if !strings.Contains(line, time.Now().Format(" T=2006-01-02 ")) {
+				t.Fatal("EncodeTime of NewDevelopmentConfigWith is not respected. output: " + line)
+			}
+		} else {
+			if !strings.Contains(line, fmt.Sprintf(" ts=%d", time.Now().UnixMilli()/1e6)) {
+				t.Fatal("EncodeTime of NewProductionConfig is not respected. output: " + line)
+			}
+		}
+	}
+}
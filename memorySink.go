@@ -2,59 +2,286 @@ package slog
 
 import (
 	"bytes"
+	"fmt"
 	"go.uber.org/zap"
+	"io"
+	"net/http"
 	"net/url"
+	"strconv"
+	"strings"
 	"sync"
 )
 
+const defaultRingSinkCapacity = 1000
+
 var (
-	_ zap.Sink = &memorySink{}
+	_ zap.Sink     = &MemorySink{}
+	_ zap.Sink     = &RingSink{}
+	_ http.Handler = &RingSink{}
 )
 
 var (
 	sinkRegistry struct {
 		once sync.Once
+		// err is the error of registering the memory scheme, which Scavenger relies on.
+		err error
 		sync.Mutex
-		m map[string]*memorySink
+		memory map[string]*MemorySink
+		ring   map[string]*RingSink
 	}
 )
 
 func initRegistry() {
-	sinkRegistry.m = make(map[string]*memorySink)
-	if err := registerMemorySinkFactory(); err != nil {
-		panic(err)
-	}
+	sinkRegistry.memory = make(map[string]*MemorySink)
+	sinkRegistry.ring = make(map[string]*RingSink)
+	sinkRegistry.err = zap.RegisterSink("memory", newMemorySinkFromURL)
+	// The ring scheme is left to another package that has registered it.
+	_ = zap.RegisterSink("ring", newRingSinkFromURL)
 }
 
-func registerMemorySinkFactory() error {
-	return zap.RegisterSink("memory", func(u *url.URL) (zap.Sink, error) {
-		if len(u.Host) == 0 {
-			panic("invalid memory sink name")
-		}
+func newMemorySinkFromURL(u *url.URL) (zap.Sink, error) {
+	if len(u.Host) == 0 {
+		return nil, fmt.Errorf("the name of the memory sink is missing: %s", u)
+	}
 
-		sinkRegistry.Lock()
-		sink := sinkRegistry.m[u.Host]
-		sinkRegistry.Unlock()
-		if sink == nil {
-			panic("impossible")
+	sinkRegistry.Lock()
+	defer sinkRegistry.Unlock()
+	sink := sinkRegistry.memory[u.Host]
+	if sink == nil {
+		sink = &MemorySink{}
+		sinkRegistry.memory[u.Host] = sink
+	}
+	return sink, nil
+}
+
+func newRingSinkFromURL(u *url.URL) (zap.Sink, error) {
+	if len(u.Host) == 0 {
+		return nil, fmt.Errorf("the name of the ring sink is missing: %s", u)
+	}
+	capacity := defaultRingSinkCapacity
+	if v := u.Query().Get("size"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n <= 0 {
+			return nil, fmt.Errorf("invalid size of the ring sink: %q", v)
 		}
+		capacity = n
+	}
 
-		return sink, nil
-	})
+	sinkRegistry.Lock()
+	defer sinkRegistry.Unlock()
+	sink := sinkRegistry.ring[u.Host]
+	if sink == nil {
+		sink = newRingSink(capacity)
+		sinkRegistry.ring[u.Host] = sink
+	}
+	return sink, nil
 }
 
-type memorySink struct {
+// MemorySink is a zap.Sink that keeps everything written to it in memory. A
+// MemorySink named x can be used as memory://x in Config.OutputPaths.
+type MemorySink struct {
+	mu  sync.Mutex
 	buf bytes.Buffer
 }
 
-func (s *memorySink) Write(p []byte) (n int, err error) {
+// NewMemorySink creates a MemorySink and registers it as memory://name, replacing
+// the one previously registered under the same name.
+func NewMemorySink(name string) *MemorySink {
+	sinkRegistry.once.Do(initRegistry)
+	sink := &MemorySink{}
+	sinkRegistry.Lock()
+	sinkRegistry.memory[name] = sink
+	sinkRegistry.Unlock()
+	return sink
+}
+
+// LookupMemorySink returns the MemorySink registered as memory://name, including one
+// created by Config.Build for an unknown name, or nil.
+func LookupMemorySink(name string) *MemorySink {
+	sinkRegistry.once.Do(initRegistry)
+	sinkRegistry.Lock()
+	defer sinkRegistry.Unlock()
+	return sinkRegistry.memory[name]
+}
+
+func (s *MemorySink) Write(p []byte) (n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 	return s.buf.Write(p)
 }
 
-func (s *memorySink) Sync() error {
+func (s *MemorySink) Sync() error {
+	return nil
+}
+
+func (s *MemorySink) Close() error {
+	return nil
+}
+
+// Bytes returns a copy of everything written to the sink.
+func (s *MemorySink) Bytes() []byte {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]byte(nil), s.buf.Bytes()...)
+}
+
+func (s *MemorySink) String() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.buf.String()
+}
+
+// Lines returns what has been written to the sink, split into lines.
+func (s *MemorySink) Lines() []string {
+	str := strings.TrimSuffix(s.String(), "\n")
+	if str == "" {
+		return nil
+	}
+	return strings.Split(str, "\n")
+}
+
+// Reset discards everything written to the sink.
+func (s *MemorySink) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.buf.Reset()
+}
+
+// RingSink is a zap.Sink that keeps the last N entries written to it. A RingSink
+// named x can be used as ring://x in Config.OutputPaths, and as an http.Handler that
+// serves its entries, e.g. on a /debug/logs page.
+type RingSink struct {
+	mu      sync.Mutex
+	entries [][]byte
+	next    int
+	full    bool
+	total   uint64
+}
+
+// NewRingSink creates a RingSink that keeps the last capacity entries and registers
+// it as ring://name, replacing the one previously registered under the same name.
+func NewRingSink(name string, capacity int) *RingSink {
+	if capacity <= 0 {
+		panic("capacity must be positive")
+	}
+	sinkRegistry.once.Do(initRegistry)
+	sink := newRingSink(capacity)
+	sinkRegistry.Lock()
+	sinkRegistry.ring[name] = sink
+	sinkRegistry.Unlock()
+	return sink
+}
+
+func newRingSink(capacity int) *RingSink {
+	return &RingSink{entries: make([][]byte, capacity)}
+}
+
+// LookupRingSink returns the RingSink registered as ring://name, including one created
+// by Config.Build for an unknown name, or nil. Such a RingSink keeps the number of
+// entries given by the size query parameter, 1000 by default.
+func LookupRingSink(name string) *RingSink {
+	sinkRegistry.once.Do(initRegistry)
+	sinkRegistry.Lock()
+	defer sinkRegistry.Unlock()
+	return sinkRegistry.ring[name]
+}
+
+// Write saves p as an entry, evicting the oldest entry if the sink is full.
+func (s *RingSink) Write(p []byte) (n int, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.entries[s.next] = append(s.entries[s.next][:0], p...)
+	s.next++
+	if s.next == len(s.entries) {
+		s.next = 0
+		s.full = true
+	}
+	s.total++
+	return len(p), nil
+}
+
+func (s *RingSink) Sync() error {
 	return nil
 }
 
-func (s *memorySink) Close() error {
+func (s *RingSink) Close() error {
 	return nil
 }
+
+// Cap returns the number of entries the sink can keep.
+func (s *RingSink) Cap() int {
+	return len(s.entries)
+}
+
+// Len returns the number of entries the sink keeps now.
+func (s *RingSink) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.full {
+		return len(s.entries)
+	}
+	return s.next
+}
+
+// Total returns the number of entries ever written to the sink, including evicted ones.
+func (s *RingSink) Total() uint64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.total
+}
+
+func (s *RingSink) rangeEntries(fn func(entry []byte) bool) {
+	if s.full {
+		for _, e := range s.entries[s.next:] {
+			if !fn(e) {
+				return
+			}
+		}
+	}
+	for _, e := range s.entries[:s.next] {
+		if !fn(e) {
+			return
+		}
+	}
+}
+
+// Entries returns the entries the sink keeps, the oldest first, without their line
+// endings.
+func (s *RingSink) Entries() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var ret []string
+	s.rangeEntries(func(entry []byte) bool {
+		ret = append(ret, strings.TrimRight(string(entry), "\r\n"))
+		return true
+	})
+	return ret
+}
+
+// WriteTo writes the entries the sink keeps to w, the oldest first.
+func (s *RingSink) WriteTo(w io.Writer) (n int64, err error) {
+	var buf bytes.Buffer
+	s.mu.Lock()
+	s.rangeEntries(func(entry []byte) bool {
+		buf.Write(entry)
+		return true
+	})
+	s.mu.Unlock()
+	return buf.WriteTo(w)
+}
+
+// Reset discards the entries the sink keeps.
+func (s *RingSink) Reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i := range s.entries {
+		s.entries[i] = nil
+	}
+	s.next, s.full = 0, false
+}
+
+// ServeHTTP serves the entries the sink keeps as plain text, the oldest first.
+func (s *RingSink) ServeHTTP(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	_, _ = s.WriteTo(w)
+}
@@ -0,0 +1,77 @@
+package slog
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMemorySink(t *testing.T) {
+	sink := NewMemorySink("TestMemorySink")
+	cfg := NewProductionConfig()
+	cfg.Encoding = EncodingLogfmt
+	cfg.EncoderConfig.TimeKey = ""
+	cfg.EncoderConfig.CallerKey = ""
+	cfg.OutputPaths = []string{"memory://TestMemorySink"}
+	logger := cfg.MustBuild()
+	logger.Info("a")
+	logger.Warn("b")
+
+	if !reflect.DeepEqual(sink.Lines(), []string{"level=info msg=a", "level=warn msg=b"}) {
+		t.Fatalf("MemorySink does not work as expected: %q", sink.Lines())
+	}
+	if LookupMemorySink("TestMemorySink") != sink {
+		t.Fatal("LookupMemorySink does not work as expected")
+	}
+	sink.Reset()
+	if sink.String() != "" || sink.Lines() != nil {
+		t.Fatal("Reset does not work as expected")
+	}
+}
+
+func TestRingSink(t *testing.T) {
+	sink := NewRingSink("TestRingSink", 3)
+	for i := 0; i < 2; i++ {
+		_, _ = fmt.Fprintf(sink, "line %d\n", i)
+	}
+	if !reflect.DeepEqual(sink.Entries(), []string{"line 0", "line 1"}) || sink.Len() != 2 {
+		t.Fatalf("RingSink does not work as expected: %q", sink.Entries())
+	}
+	for i := 2; i < 7; i++ {
+		_, _ = fmt.Fprintf(sink, "line %d\n", i)
+	}
+	if !reflect.DeepEqual(sink.Entries(), []string{"line 4", "line 5", "line 6"}) || sink.Len() != 3 || sink.Total() != 7 {
+		t.Fatalf("RingSink does not work as expected: %q", sink.Entries())
+	}
+
+	rec := httptest.NewRecorder()
+	sink.ServeHTTP(rec, httptest.NewRequest("GET", "/debug/logs", nil))
+	if rec.Body.String() != "line 4\nline 5\nline 6\n" || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Fatal("ServeHTTP does not work as expected: " + rec.Body.String())
+	}
+
+	sink.Reset()
+	if sink.Len() != 0 || sink.Entries() != nil {
+		t.Fatal("Reset does not work as expected")
+	}
+}
+
+func TestRingSink_Config(t *testing.T) {
+	cfg := NewProductionConfig()
+	cfg.OutputPaths = []string{"ring://TestRingSink_Config?size=2"}
+	logger := cfg.MustBuild()
+	for i := 0; i < 5; i++ {
+		logger.Infof("hello %d", i)
+	}
+
+	sink := LookupRingSink("TestRingSink_Config")
+	if sink == nil || sink.Cap() != 2 {
+		t.Fatal("Config.Build should create the ring sink")
+	}
+	entries := sink.Entries()
+	if len(entries) != 2 || !strings.Contains(entries[0], `"msg":"hello 3"`) || !strings.Contains(entries[1], `"msg":"hello 4"`) {
+		t.Fatalf("RingSink does not work as expected with Config: %q", entries)
+	}
+}
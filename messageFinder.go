@@ -11,16 +11,17 @@ type MessageFinder Scavenger
 func (mf *MessageFinder) FindString(str string) []int {
 	mf.mu.Lock()
 	defer mf.mu.Unlock()
+	entries := (*Scavenger)(mf).view()
 
 	var ret []int
 	if str != "" {
-		for i, e := range mf.entries {
+		for i, e := range entries {
 			if strings.Contains(e.Message, str) {
 				ret = append(ret, i)
 			}
 		}
 	} else {
-		for i, e := range mf.entries {
+		for i, e := range entries {
 			if e.Message == "" {
 				ret = append(ret, i)
 			}
@@ -32,9 +33,10 @@ func (mf *MessageFinder) FindString(str string) []int {
 func (mf *MessageFinder) FindStringSequence(seq []string) ([]int, bool) {
 	mf.mu.Lock()
 	defer mf.mu.Unlock()
+	entries := (*Scavenger)(mf).view()
 
 	var ret []int
-	for i, e := range mf.entries {
+	for i, e := range entries {
 		j := len(ret)
 		if j >= len(seq) {
 			break
@@ -66,9 +68,10 @@ func (mf *MessageFinder) FindRegexp(pat string) []int {
 
 	mf.mu.Lock()
 	defer mf.mu.Unlock()
+	entries := (*Scavenger)(mf).view()
 
 	var ret []int
-	for i, e := range mf.entries {
+	for i, e := range entries {
 		if rex.FindStringIndex(e.Message) != nil {
 			ret = append(ret, i)
 		}
@@ -90,9 +93,10 @@ func (mf *MessageFinder) FindRegexpSequence(seq []string) ([]int, bool) {
 
 	mf.mu.Lock()
 	defer mf.mu.Unlock()
+	entries := (*Scavenger)(mf).view()
 
 	var ret []int
-	for i, e := range mf.entries {
+	for i, e := range entries {
 		j := len(ret)
 		if j >= len(seq) {
 			break
@@ -148,9 +152,10 @@ func (mf *MessageFinder) FindSequence(seq []string) ([]int, bool) {
 
 	mf.mu.Lock()
 	defer mf.mu.Unlock()
+	entries := (*Scavenger)(mf).view()
 
 	var ret []int
-	for i, e := range mf.entries {
+	for i, e := range entries {
 		j := len(ret)
 		if j >= len(seq) {
 			break
@@ -173,3 +178,101 @@ func (mf *MessageFinder) FindSequence(seq []string) ([]int, bool) {
 	ok := len(ret) == len(seq)
 	return ret, ok
 }
+
+func compileMatcher(str string) func(msg string) bool {
+	if strings.HasPrefix(str, rexPrefix) {
+		pat := strings.TrimLeftFunc(strings.TrimPrefix(str, rexPrefix), unicode.IsSpace)
+		if pat != "" {
+			rex, err := regexp.Compile(pat)
+			if err != nil {
+				panic(err)
+			}
+			return func(msg string) bool {
+				return rex.FindStringIndex(msg) != nil
+			}
+		}
+		str = ""
+	}
+	if str != "" {
+		return func(msg string) bool {
+			return strings.Contains(msg, str)
+		}
+	} else {
+		return func(msg string) bool {
+			return msg == ""
+		}
+	}
+}
+
+// FindBetween returns the indexes of the log messages matching str that lie strictly
+// between a message matching begin and the next message matching end. Every such range
+// is examined. A begin without a following end does not form a range. The second return
+// value reports whether at least one complete range was found. begin, end and str
+// follow the same rules as Find.
+func (mf *MessageFinder) FindBetween(begin, end, str string) ([]int, bool) {
+	match := compileMatcher(str)
+	return mf.FindBetweenFunc(begin, end, func(level, msg string) bool {
+		return match(msg)
+	})
+}
+
+// FindBetweenFunc is like FindBetween but uses the predicate fn to pick the log messages.
+func (mf *MessageFinder) FindBetweenFunc(begin, end string, fn func(level, msg string) bool) ([]int, bool) {
+	matchBegin := compileMatcher(begin)
+	matchEnd := compileMatcher(end)
+
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+	entries := (*Scavenger)(mf).view()
+
+	var ret, pending []int
+	var found, inRange bool
+	for i, e := range entries {
+		if !inRange {
+			if matchBegin(e.Message) {
+				inRange = true
+			}
+			continue
+		}
+		if matchEnd(e.Message) {
+			ret = append(ret, pending...)
+			pending = pending[:0]
+			inRange = false
+			found = true
+			continue
+		}
+		if fn(e.Level, e.Message) {
+			pending = append(pending, i)
+		}
+	}
+	return ret, found
+}
+
+// FindAfter returns the indexes of the log messages matching str that come after index.
+// Pass -1 to examine all the log messages. str follows the same rules as Find.
+func (mf *MessageFinder) FindAfter(index int, str string) []int {
+	match := compileMatcher(str)
+	return mf.FindAfterFunc(index, func(level, msg string) bool {
+		return match(msg)
+	})
+}
+
+// FindAfterFunc is like FindAfter but uses the predicate fn to pick the log messages.
+func (mf *MessageFinder) FindAfterFunc(index int, fn func(level, msg string) bool) []int {
+	mf.mu.Lock()
+	defer mf.mu.Unlock()
+	entries := (*Scavenger)(mf).view()
+
+	start := index + 1
+	if start < 0 {
+		start = 0
+	}
+
+	var ret []int
+	for i := start; i < len(entries); i++ {
+		if e := entries[i]; fn(e.Level, e.Message) {
+			ret = append(ret, i)
+		}
+	}
+	return ret
+}
@@ -0,0 +1,171 @@
+package slog
+
+import (
+	"bytes"
+	"encoding/json"
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"strconv"
+)
+
+const (
+	// EncodingOTel is the Config.Encoding value that selects the OpenTelemetry log data
+	// model encoder.
+	EncodingOTel = "otel"
+	// OTelTraceIDKey and OTelSpanIDKey are the keys of the string fields that the
+	// OpenTelemetry encoder moves to TraceId and SpanId.
+	OTelTraceIDKey = "trace_id"
+	OTelSpanIDKey  = "span_id"
+)
+
+var (
+	_ zapcore.Encoder = &otelEncoder{}
+)
+
+type otelEncoder struct {
+	// Encoder renders Attributes. All of its entry keys are empty.
+	zapcore.Encoder
+	lineEnding string
+	traceID    string
+	spanID     string
+}
+
+// NewOTelEncoder creates an encoder that writes the OpenTelemetry log data model as
+// JSON: Timestamp (in nanoseconds since the Unix epoch), SeverityText, SeverityNumber,
+// Body, Attributes, TraceId, SpanId and InstrumentationScope, which holds the logger
+// name. The caller and the first error of an entry are written as code.* and
+// exception.* attributes, following the semantic conventions. The fields keyed
+// "trace_id" and "span_id" become TraceId and SpanId.
+func NewOTelEncoder(cfg zapcore.EncoderConfig) zapcore.Encoder {
+	lineEnding := cfg.LineEnding
+	if cfg.SkipLineEnding {
+		lineEnding = ""
+	} else if lineEnding == "" {
+		lineEnding = zapcore.DefaultLineEnding
+	}
+	cfg.TimeKey = ""
+	cfg.LevelKey = ""
+	cfg.NameKey = ""
+	cfg.CallerKey = ""
+	cfg.FunctionKey = ""
+	cfg.MessageKey = ""
+	cfg.StacktraceKey = ""
+	cfg.SkipLineEnding = true
+	if cfg.EncodeTime == nil {
+		cfg.EncodeTime = zapcore.RFC3339NanoTimeEncoder
+	}
+	return &otelEncoder{
+		Encoder:    zapcore.NewJSONEncoder(cfg),
+		lineEnding: lineEnding,
+	}
+}
+
+func (enc *otelEncoder) Clone() zapcore.Encoder {
+	return &otelEncoder{
+		Encoder:    enc.Encoder.Clone(),
+		lineEnding: enc.lineEnding,
+		traceID:    enc.traceID,
+		spanID:     enc.spanID,
+	}
+}
+
+func (enc *otelEncoder) AddString(key, value string) {
+	switch key {
+	case OTelTraceIDKey:
+		enc.traceID = value
+	case OTelSpanIDKey:
+		enc.spanID = value
+	default:
+		enc.Encoder.AddString(key, value)
+	}
+}
+
+func (enc *otelEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	traceID, spanID := enc.traceID, enc.spanID
+	attrs := make([]zapcore.Field, 0, len(fields)+4)
+	if ent.Caller.Defined {
+		attrs = append(attrs,
+			zapcore.Field{Key: "code.filepath", Type: zapcore.StringType, String: ent.Caller.File},
+			zapcore.Field{Key: "code.lineno", Type: zapcore.Int64Type, Integer: int64(ent.Caller.Line)})
+		if ent.Caller.Function != "" {
+			attrs = append(attrs, zapcore.Field{Key: "code.function", Type: zapcore.StringType, String: ent.Caller.Function})
+		}
+	}
+	for _, f := range fields {
+		switch {
+		case f.Type == zapcore.StringType && f.Key == OTelTraceIDKey:
+			traceID = f.String
+		case f.Type == zapcore.StringType && f.Key == OTelSpanIDKey:
+			spanID = f.String
+		default:
+			attrs = append(attrs, f)
+		}
+	}
+	attrs = flattenFirstError(attrs, "exception.message", "exception.type", "exception.stacktrace")
+	if ent.Stack != "" {
+		attrs = append(attrs, zapcore.Field{Key: "code.stacktrace", Type: zapcore.StringType, String: ent.Stack})
+	}
+
+	encoded, err := enc.Encoder.EncodeEntry(zapcore.Entry{}, attrs)
+	if err != nil {
+		return nil, err
+	}
+	defer encoded.Free()
+
+	buf := _bufferPool.Get()
+	buf.AppendString(`{"Timestamp":"`)
+	buf.AppendString(strconv.FormatInt(ent.Time.UnixNano(), 10))
+	buf.AppendString(`","SeverityText":"`)
+	buf.AppendString(ent.Level.CapitalString())
+	buf.AppendString(`","SeverityNumber":`)
+	buf.AppendInt(int64(otelSeverityNumber(ent.Level)))
+	buf.AppendString(`,"Body":`)
+	appendJSONString(buf, ent.Message)
+	buf.AppendString(`,"Attributes":`)
+	buf.Write(encoded.Bytes())
+	if traceID != "" {
+		buf.AppendString(`,"TraceId":`)
+		appendJSONString(buf, traceID)
+	}
+	if spanID != "" {
+		buf.AppendString(`,"SpanId":`)
+		appendJSONString(buf, spanID)
+	}
+	if ent.LoggerName != "" {
+		buf.AppendString(`,"InstrumentationScope":{"Name":`)
+		appendJSONString(buf, ent.LoggerName)
+		buf.AppendByte('}')
+	}
+	buf.AppendByte('}')
+	buf.AppendString(enc.lineEnding)
+	return buf, nil
+}
+
+// otelSeverityNumber maps a level to the first SeverityNumber of its range, e.g. 9
+// (INFO) for InfoLevel.
+func otelSeverityNumber(level zapcore.Level) int {
+	switch {
+	case level < zapcore.DebugLevel:
+		return 1
+	case level == zapcore.DebugLevel:
+		return 5
+	case level == zapcore.InfoLevel:
+		return 9
+	case level == zapcore.WarnLevel:
+		return 13
+	case level == zapcore.ErrorLevel:
+		return 17
+	case level == zapcore.DPanicLevel || level == zapcore.PanicLevel:
+		return 18
+	default:
+		return 21
+	}
+}
+
+func appendJSONString(buf *buffer.Buffer, s string) {
+	var b bytes.Buffer
+	e := json.NewEncoder(&b)
+	e.SetEscapeHTML(false)
+	_ = e.Encode(s)
+	buf.Write(bytes.TrimSuffix(b.Bytes(), []byte{'\n'}))
+}
@@ -0,0 +1,83 @@
+package slog
+
+import (
+	"encoding/json"
+	"errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOTelEncoder(t *testing.T) {
+	enc := NewOTelEncoder(zap.NewProductionEncoderConfig())
+	enc.AddString("handler", "UpdateUserName")
+	enc.AddString(OTelTraceIDKey, "0af7651916cd43dd8448eb211c80319c")
+	ent := zapcore.Entry{
+		Level:      zapcore.WarnLevel,
+		Time:       time.Unix(1700000000, 123),
+		LoggerName: "app",
+		Caller:     zapcore.NewEntryCaller(0, "/src/app/main.go", 12, true),
+		Message:    "<slow> query",
+	}
+	m := encodeJSONEntry(t, enc, ent,
+		zap.String(OTelSpanIDKey, "b7ad6b7169203331"),
+		zap.Error(errors.New("timeout")),
+		zap.Int("n", 1))
+
+	expected := map[string]any{
+		"Timestamp":      "1700000000000000123",
+		"SeverityText":   "WARN",
+		"SeverityNumber": float64(13),
+		"Body":           "<slow> query",
+		"TraceId":        "0af7651916cd43dd8448eb211c80319c",
+		"SpanId":         "b7ad6b7169203331",
+		"Attributes": map[string]any{
+			"handler":           "UpdateUserName",
+			"code.filepath":     "/src/app/main.go",
+			"code.lineno":       float64(12),
+			"exception.message": "timeout",
+			"exception.type":    "*errors.errorString",
+			"n":                 float64(1),
+		},
+		"InstrumentationScope": map[string]any{"Name": "app"},
+	}
+	if !reflect.DeepEqual(m, expected) {
+		t.Fatalf("NewOTelEncoder does not work as expected: %v", m)
+	}
+
+	m = encodeJSONEntry(t, enc.Clone(), zapcore.Entry{Level: zapcore.DebugLevel, Message: "x"})
+	if m["SpanId"] != nil || m["TraceId"] != "0af7651916cd43dd8448eb211c80319c" || m["SeverityNumber"] != float64(5) {
+		t.Fatalf("NewOTelEncoder does not work as expected: %v", m)
+	}
+}
+
+func TestConfig_OTel(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.json")
+	cfg := NewProductionConfig()
+	cfg.Encoding = EncodingOTel
+	cfg.OutputPaths = []string{path}
+	logger := cfg.MustBuild()
+	logger.NewLoggerWith(OTelTraceIDKey, "t1").Infow("hello", OTelSpanIDKey, "s1", "user", "tom")
+	_ = logger.FlushLogger()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(string(data), "}\n") {
+		t.Fatal("a line ending is expected")
+	}
+	m := make(map[string]any)
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	attrs, _ := m["Attributes"].(map[string]any)
+	if m["Body"] != "hello" || m["TraceId"] != "t1" || m["SpanId"] != "s1" || attrs["user"] != "tom" {
+		t.Fatal("Config does not work as expected with the otel encoding. output: " + string(data))
+	}
+}
@@ -0,0 +1,278 @@
+package slog
+
+import (
+	"go.uber.org/zap/buffer"
+	"go.uber.org/zap/zapcore"
+	"golang.org/x/term"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"unicode/utf8"
+)
+
+const (
+	// EncodingPretty is the Config.Encoding value that selects the pretty console encoder.
+	EncodingPretty = "pretty"
+)
+
+const (
+	defaultPrettyWrapWidth   = 120
+	defaultPrettyCallerWidth = 20
+)
+
+const (
+	_colorReset   = "\x1b[0m"
+	_colorRed     = "\x1b[31m"
+	_colorGreen   = "\x1b[32m"
+	_colorYellow  = "\x1b[33m"
+	_colorBlue    = "\x1b[34m"
+	_colorMagenta = "\x1b[35m"
+	_colorCyan    = "\x1b[36m"
+	_colorGray    = "\x1b[90m"
+)
+
+var (
+	_ zapcore.Encoder = &prettyEncoder{}
+)
+
+type prettyOptions struct {
+	colors    bool
+	wrapWidth int
+}
+
+// PrettyOption configures the pretty console encoder.
+type PrettyOption func(opts *prettyOptions)
+
+// PrettyColors turns colors on or off. By default, colors are enabled only when
+// stdout is a terminal and the NO_COLOR environment variable is not set.
+func PrettyColors(enabled bool) PrettyOption {
+	return func(opts *prettyOptions) {
+		opts.colors = enabled
+	}
+}
+
+// PrettyWrapWidth sets the width at which long messages are wrapped. Zero or a
+// negative value disables wrapping. The default width is 120.
+func PrettyWrapWidth(n int) PrettyOption {
+	return func(opts *prettyOptions) {
+		opts.wrapWidth = n
+	}
+}
+
+func colorsByDefault() bool {
+	if _, ok := os.LookupEnv("NO_COLOR"); ok {
+		return false
+	}
+	return term.IsTerminal(int(os.Stdout.Fd()))
+}
+
+type prettyEncoder struct {
+	*fieldCollector
+	opts prettyOptions
+	// callerWidth is shared by the clones of an encoder so that the caller column
+	// widens for every logger at once.
+	callerWidth *int64
+}
+
+// NewPrettyEncoder creates a human-friendly console encoder. The level and caller
+// columns are aligned, fields are printed as key=value pairs colored by type,
+// multi-line values are printed as indented blocks, and long messages are wrapped.
+// EncodeLevel is ignored because the encoder colors levels itself. It is what
+// Config.Build uses when Encoding is "pretty".
+func NewPrettyEncoder(cfg zapcore.EncoderConfig, opts ...PrettyOption) zapcore.Encoder {
+	options := prettyOptions{
+		colors:    colorsByDefault(),
+		wrapWidth: defaultPrettyWrapWidth,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if cfg.SkipLineEnding {
+		cfg.LineEnding = ""
+	} else if cfg.LineEnding == "" {
+		cfg.LineEnding = zapcore.DefaultLineEnding
+	}
+	enc := &prettyEncoder{
+		fieldCollector: &fieldCollector{cfg: &cfg},
+		opts:           options,
+		callerWidth:    new(int64),
+	}
+	*enc.callerWidth = defaultPrettyCallerWidth
+	return enc
+}
+
+func (enc *prettyEncoder) Clone() zapcore.Encoder {
+	return &prettyEncoder{
+		fieldCollector: enc.fieldCollector.clone(),
+		opts:           enc.opts,
+		callerWidth:    enc.callerWidth,
+	}
+}
+
+func (enc *prettyEncoder) EncodeEntry(ent zapcore.Entry, fields []zapcore.Field) (*buffer.Buffer, error) {
+	fc := enc.fieldCollector.clone()
+	for i := range fields {
+		fields[i].AddTo(fc)
+	}
+
+	cfg := enc.cfg
+	buf := _bufferPool.Get()
+	var indent int
+	column := func(color, s string, width int) {
+		n := utf8.RuneCountInString(s)
+		enc.colorize(buf, color, s)
+		if n < width {
+			buf.AppendString(strings.Repeat(" ", width-n))
+			n = width
+		}
+		buf.AppendByte(' ')
+		indent += n + 1
+	}
+
+	// Unlike zap's console encoder, the time goes first so that the columns after it
+	// line up.
+	header := entryHeader(cfg, ent)
+	sort.SliceStable(header, func(i, j int) bool {
+		return header[i].kind == kindTime && header[j].kind != kindTime
+	})
+
+	var message string
+	for _, h := range header {
+		switch h.key {
+		case cfg.TimeKey:
+			column(_colorGray, h.value, 0)
+		case cfg.LevelKey:
+			column(levelColor(ent.Level), ent.Level.CapitalString(), 5)
+		case cfg.NameKey:
+			column("", "["+h.value+"]", 0)
+		case cfg.CallerKey:
+			column(_colorGray, h.value, enc.widenCallerColumn(utf8.RuneCountInString(h.value)))
+		case cfg.FunctionKey:
+			column("", h.value, 0)
+		case cfg.MessageKey:
+			message = h.value
+		}
+	}
+
+	enc.appendMessage(buf, message, indent)
+	var blocks []flatField
+	for _, f := range fc.fields {
+		if strings.Contains(f.value, "\n") {
+			blocks = append(blocks, f)
+			continue
+		}
+		buf.AppendString("  ")
+		enc.colorize(buf, _colorCyan, f.key)
+		buf.AppendByte('=')
+		value := f.value
+		if logfmtNeedsQuote(value) {
+			value = strconv.Quote(value)
+		}
+		enc.colorize(buf, kindColor(f.kind), value)
+	}
+	if ent.Stack != "" && cfg.StacktraceKey != "" {
+		blocks = append(blocks, flatField{key: cfg.StacktraceKey, value: ent.Stack})
+	}
+	for _, b := range blocks {
+		buf.AppendString("\n    ")
+		enc.colorize(buf, _colorCyan, b.key)
+		buf.AppendByte(':')
+		for _, line := range strings.Split(strings.TrimRight(b.value, "\n"), "\n") {
+			buf.AppendString("\n        ")
+			enc.colorize(buf, kindColor(b.kind), line)
+		}
+	}
+	buf.AppendString(cfg.LineEnding)
+	return buf, nil
+}
+
+// widenCallerColumn makes the caller column at least n characters wide and returns
+// its width.
+func (enc *prettyEncoder) widenCallerColumn(n int) int {
+	for {
+		width := atomic.LoadInt64(enc.callerWidth)
+		if int64(n) <= width {
+			return int(width)
+		}
+		if atomic.CompareAndSwapInt64(enc.callerWidth, width, int64(n)) {
+			return n
+		}
+	}
+}
+
+// appendMessage writes the message, wrapping it at word boundaries. Continuation
+// lines, including those of multi-line messages, are indented to the message column.
+func (enc *prettyEncoder) appendMessage(buf *buffer.Buffer, message string, indent int) {
+	for i, line := range strings.Split(message, "\n") {
+		if i > 0 {
+			buf.AppendByte('\n')
+			buf.AppendString(strings.Repeat(" ", indent))
+		}
+		enc.appendWrapped(buf, line, indent)
+	}
+}
+
+func (enc *prettyEncoder) appendWrapped(buf *buffer.Buffer, line string, indent int) {
+	width := enc.opts.wrapWidth - indent
+	if enc.opts.wrapWidth <= 0 || width < 20 || utf8.RuneCountInString(line) <= width {
+		buf.AppendString(line)
+		return
+	}
+
+	var n int
+	for i, word := range strings.Fields(line) {
+		w := utf8.RuneCountInString(word)
+		switch {
+		case i == 0:
+		case n+1+w > width:
+			buf.AppendByte('\n')
+			buf.AppendString(strings.Repeat(" ", indent))
+			n = 0
+		default:
+			buf.AppendByte(' ')
+			n++
+		}
+		buf.AppendString(word)
+		n += w
+	}
+}
+
+func (enc *prettyEncoder) colorize(buf *buffer.Buffer, color, s string) {
+	if !enc.opts.colors || color == "" {
+		buf.AppendString(s)
+		return
+	}
+	buf.AppendString(color)
+	buf.AppendString(s)
+	buf.AppendString(_colorReset)
+}
+
+func levelColor(level zapcore.Level) string {
+	switch {
+	case level <= zapcore.DebugLevel:
+		return _colorMagenta
+	case level == zapcore.InfoLevel:
+		return _colorBlue
+	case level == zapcore.WarnLevel:
+		return _colorYellow
+	default:
+		return _colorRed
+	}
+}
+
+func kindColor(kind valueKind) string {
+	switch kind {
+	case kindNumber:
+		return _colorBlue
+	case kindBool:
+		return _colorYellow
+	case kindTime, kindDuration:
+		return _colorMagenta
+	case kindNull:
+		return _colorGray
+	default:
+		return _colorGreen
+	}
+}
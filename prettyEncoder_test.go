@@ -0,0 +1,137 @@
+package slog
+
+import (
+	"errors"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newTestPrettyEncoder(opts ...PrettyOption) zapcore.Encoder {
+	cfg := zap.NewDevelopmentEncoderConfig()
+	cfg.EncodeTime = func(t time.Time, enc zapcore.PrimitiveArrayEncoder) {
+		enc.AppendString(t.Format("15:04:05"))
+	}
+	return NewPrettyEncoder(cfg, opts...)
+}
+
+func prettyEntry(level zapcore.Level, file string, msg string) zapcore.Entry {
+	return zapcore.Entry{
+		Level:   level,
+		Time:    time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC),
+		Caller:  zapcore.NewEntryCaller(0, "/src/app/"+file, 12, true),
+		Message: msg,
+	}
+}
+
+func TestPrettyEncoder(t *testing.T) {
+	enc := newTestPrettyEncoder(PrettyColors(false))
+	enc.AddString("handler", "UpdateUserName")
+
+	buf, err := enc.EncodeEntry(prettyEntry(zapcore.InfoLevel, "main.go", "hello"), []zapcore.Field{
+		zap.Int("n", 7),
+		zap.String("s", "a b"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected1 = "03:04:05 INFO  app/main.go:12       hello  handler=UpdateUserName  n=7  s=\"a b\"\n"
+	if buf.String() != expected1 {
+		t.Fatal("NewPrettyEncoder does not work as expected. output: " + buf.String())
+	}
+
+	buf, err = enc.EncodeEntry(prettyEntry(zapcore.ErrorLevel, "internal/handler.go", "failed"), []zapcore.Field{
+		zap.String("body", "line1\nline2"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected2 = "03:04:05 ERROR internal/handler.go:12 failed  handler=UpdateUserName\n" +
+		"    body:\n        line1\n        line2\n"
+	if buf.String() != expected2 {
+		t.Fatal("multi-line values are not indented as expected. output: " + buf.String())
+	}
+
+	buf, err = enc.Clone().EncodeEntry(prettyEntry(zapcore.WarnLevel, "main.go", "aligned"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	const expected3 = "03:04:05 WARN  app/main.go:12         aligned  handler=UpdateUserName\n"
+	if buf.String() != expected3 {
+		t.Fatal("the caller column does not widen as expected. output: " + buf.String())
+	}
+}
+
+func TestPrettyEncoder_Wrap(t *testing.T) {
+	enc := newTestPrettyEncoder(PrettyColors(false), PrettyWrapWidth(60))
+	msg := strings.Repeat("lorem ipsum ", 5) + "\nsecond line"
+	buf, err := enc.EncodeEntry(prettyEntry(zapcore.DebugLevel, "main.go", msg), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	indent := strings.Repeat(" ", 36)
+	expected := "03:04:05 DEBUG app/main.go:12       lorem ipsum lorem ipsum\n" +
+		indent + "lorem ipsum lorem ipsum\n" +
+		indent + "lorem ipsum\n" +
+		indent + "second line\n"
+	if buf.String() != expected {
+		t.Fatal("long messages are not wrapped as expected. output: " + buf.String())
+	}
+}
+
+func TestPrettyEncoder_Colors(t *testing.T) {
+	enc := newTestPrettyEncoder(PrettyColors(true))
+	buf, err := enc.EncodeEntry(prettyEntry(zapcore.WarnLevel, "main.go", "hi"), []zapcore.Field{
+		zap.Int("n", 1),
+		zap.Bool("ok", true),
+		zap.String("s", "x"),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []string{
+		_colorYellow + "WARN" + _colorReset,
+		_colorCyan + "n" + _colorReset + "=" + _colorBlue + "1" + _colorReset,
+		_colorCyan + "ok" + _colorReset + "=" + _colorYellow + "true" + _colorReset,
+		_colorCyan + "s" + _colorReset + "=" + _colorGreen + "x" + _colorReset,
+	} {
+		if !strings.Contains(buf.String(), s) {
+			t.Fatalf("fields are not colored as expected. output: %q", buf.String())
+		}
+	}
+
+	t.Setenv("NO_COLOR", "1")
+	buf, err = newTestPrettyEncoder().EncodeEntry(prettyEntry(zapcore.WarnLevel, "main.go", "hi"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(buf.String(), "\x1b[") {
+		t.Fatal("colors should be disabled when NO_COLOR is set")
+	}
+}
+
+func TestConfig_Pretty(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.txt")
+	cfg := NewDevelopmentConfig()
+	cfg.Encoding = EncodingPretty
+	cfg.OutputPaths = []string{path}
+	cfg.StructuredErrors = true
+	logger := cfg.MustBuild()
+	logger.Errorw("oops", "err", errors.New("boom"))
+	_ = logger.FlushLogger()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "\x1b[") {
+		t.Fatal("colors should be disabled when stdout is not a terminal")
+	}
+	if !strings.Contains(string(data), " ERROR ") || !strings.Contains(string(data), " oops  err.message=boom  err.type=*errors.errorString\n") {
+		t.Fatal("Config does not work as expected with the pretty encoding. output: " + string(data))
+	}
+}
@@ -0,0 +1,36 @@
+package slog
+
+import (
+	"runtime/debug"
+)
+
+func logPanic(l Logger, r any) {
+	l.Errorw("panic recovered", "panic", r, "stack", string(debug.Stack()))
+}
+
+// Recover recovers from a panic and logs the panic value and the goroutine stack through l
+// at Error. It must be called directly by a deferred statement:
+//
+//	defer slog.Recover(logger)
+func Recover(l Logger) {
+	if r := recover(); r != nil {
+		logPanic(l, r)
+	}
+}
+
+// RecoverAndRepanic is like Recover but panics again with the same value after logging.
+func RecoverAndRepanic(l Logger) {
+	if r := recover(); r != nil {
+		logPanic(l, r)
+		_ = l.FlushLogger()
+		panic(r)
+	}
+}
+
+// Go runs fn in a new goroutine. A panic in fn is recovered and logged through l.
+func Go(l Logger, fn func()) {
+	go func() {
+		defer Recover(l)
+		fn()
+	}()
+}
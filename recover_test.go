@@ -0,0 +1,72 @@
+package slog
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func crash() {
+	panic("boom")
+}
+
+func TestRecover(t *testing.T) {
+	sc := NewScavenger()
+	func() {
+		defer Recover(sc.NewLoggerWith("worker", 1))
+		crash()
+	}()
+	func() {
+		defer Recover(sc)
+	}()
+
+	if sc.Len() != 1 {
+		t.Fatal(`sc.Len() != 1`)
+	}
+	e := sc.LogEntry(0)
+	if e.Level != LevelError || !strings.HasPrefix(e.Message, `panic recovered	{"worker": 1, "panic": "boom", "stack": "goroutine `) {
+		t.Fatal("something is wrong with Recover: " + e.Message)
+	}
+	if !strings.Contains(e.Message, "slog.crash(") {
+		t.Fatal("the stack should contain the panicking function: " + e.Message)
+	}
+}
+
+func TestRecoverAndRepanic(t *testing.T) {
+	sc := NewScavenger()
+	defer func() {
+		if r := recover(); r != "boom" {
+			t.Fatal("RecoverAndRepanic should panic again")
+		}
+		if !sc.Exists("panic recovered") {
+			t.Fatal("RecoverAndRepanic should log the panic")
+		}
+	}()
+	defer RecoverAndRepanic(sc)
+	crash()
+}
+
+func TestGo(t *testing.T) {
+	sc := NewScavenger()
+	ch := make(chan LogEntry, 1)
+	sc.SubscribeChan(nil, ch)
+
+	done := make(chan struct{})
+	Go(sc, func() {
+		close(done)
+	})
+	<-done
+	Go(sc, crash)
+
+	select {
+	case e := <-ch:
+		if !strings.Contains(e.Message, "slog.crash(") {
+			t.Fatal("something is wrong with Go: " + e.Message)
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("the panic should be logged")
+	}
+	if sc.Len() != 1 {
+		t.Fatal(`sc.Len() != 1`)
+	}
+}
@@ -35,11 +35,86 @@ func goID() int {
 type LogEntry struct {
 	Level   string `json:"level"`
 	Message string `json:"message"`
+	// Scope holds the key-value pairs accumulated through NewLoggerWith.
+	Scope []any `json:"scope,omitempty"`
+	// Kind tells ordinary log messages from diagnostic ones.
+	Kind EntryKind `json:"kind,omitempty"`
+	// Keys holds the offending keys reported by a diagnostic entry.
+	Keys []any `json:"keys,omitempty"`
+
+	scope *scope
+}
+
+// String returns e in the format used by Dump.
+func (e LogEntry) String() string {
+	return e.Level + "\t" + e.Message
 }
 
 type entryHolder struct {
 	mu      sync.Mutex
 	entries []LogEntry
+	dropped int
+	level   zap.AtomicLevel
+	tb      TB
+	subs    []*subscriber
+
+	structuredErrors bool
+}
+
+// TB is the subset of testing.TB used by Scavenger.
+type TB interface {
+	Log(args ...any)
+	Cleanup(fn func())
+	Failed() bool
+}
+
+type scavengerOptions struct {
+	level            zapcore.Level
+	tb               TB
+	tbOnFailure      TB
+	structuredErrors bool
+}
+
+// ScavengerOption configures a Scavenger.
+type ScavengerOption func(opts *scavengerOptions)
+
+// WithMinLevel sets the minimum enabled log level of a Scavenger, e.g. ZapInfoLevel.
+// Log messages below the level are neither collected nor reported as enabled by
+// LogLevelEnabled. The default level is ZapDebugLevel.
+func WithMinLevel(level int) ScavengerOption {
+	return func(opts *scavengerOptions) {
+		opts.level = zapcore.Level(level)
+	}
+}
+
+// WithTestLog makes a Scavenger forward every collected log message to tb.Log,
+// so that `go test -v` shows what the code under test logged.
+func WithTestLog(tb TB) ScavengerOption {
+	return func(opts *scavengerOptions) {
+		opts.tb = tb
+	}
+}
+
+// WithTestLogOnFailure makes a Scavenger replay all the collected log messages through
+// tb.Log when the test fails. The replay is registered with tb.Cleanup.
+func WithTestLogOnFailure(tb TB) ScavengerOption {
+	return func(opts *scavengerOptions) {
+		opts.tbOnFailure = tb
+	}
+}
+
+// WithStructuredErrors makes a Scavenger render errors as structured fields, like
+// ZapLoggerStructuredErrors does for a ZapLogger.
+func WithStructuredErrors() ScavengerOption {
+	return func(opts *scavengerOptions) {
+		opts.structuredErrors = true
+	}
+}
+
+// Checkpoint marks a position in the log messages collected by a Scavenger.
+type Checkpoint struct {
+	holder *entryHolder
+	n      int
 }
 
 // Scavenger collects all log messages for later queries.
@@ -47,21 +122,35 @@ type Scavenger struct {
 	*entryHolder
 	buf *bytes.Buffer
 
-	x   zap.SugaredLogger
-	kvs []any
+	x      zap.SugaredLogger
+	kvs    []any
+	fields []any
+	scope  *scope
+	since  int
+	filter func(e *LogEntry) bool
 }
 
 // NewScavenger creates a new Scavenger.
-func NewScavenger() *Scavenger {
+func NewScavenger(opts ...ScavengerOption) *Scavenger {
+	options := scavengerOptions{
+		level: zapcore.DebugLevel,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	sinkRegistry.once.Do(initRegistry)
+	if sinkRegistry.err != nil {
+		panic(sinkRegistry.err)
+	}
 	sinkName := fmt.Sprintf("scavenger-%d", goID())
-	sink := &memorySink{}
+	sink := &MemorySink{}
 	sinkRegistry.Lock()
-	sinkRegistry.m[sinkName] = sink
+	sinkRegistry.memory[sinkName] = sink
 	sinkRegistry.Unlock()
 	defer func() {
 		sinkRegistry.Lock()
-		delete(sinkRegistry.m, sinkName)
+		delete(sinkRegistry.memory, sinkName)
 		sinkRegistry.Unlock()
 	}()
 
@@ -81,175 +170,327 @@ func NewScavenger() *Scavenger {
 	var sc Scavenger
 	sc.x = *l.Sugar()
 	sc.buf = &sink.buf
-	sc.entryHolder = &entryHolder{}
+	sc.entryHolder = &entryHolder{
+		level:            zap.NewAtomicLevelAt(options.level),
+		tb:               options.tb,
+		structuredErrors: options.structuredErrors,
+	}
+	sc.scope = &scope{}
+	if tb := options.tbOnFailure; tb != nil {
+		tb.Cleanup(func() {
+			if tb.Failed() {
+				tb.Log("collected log messages:\n" + sc.Dump())
+			}
+		})
+	}
 	return &sc
 }
 
 func (sc *Scavenger) NewLoggerWith(keyVals ...any) Logger {
-	kvs := append(sc.kvs, keyVals...)
+	kvs := make([]any, 0, len(sc.kvs)+len(keyVals))
+	kvs = append(kvs, sc.kvs...)
+	kvs = append(kvs, keyVals...)
+	newFields, diags := checkKeyVals(keyVals, sc.structuredErrors)
+	fields := make([]any, 0, len(sc.fields)+len(newFields))
+	fields = append(fields, sc.fields...)
+	fields = append(fields, newFields...)
+
 	scav := NewScavenger()
-	scav.x = *scav.x.With(kvs...)
+	scav.x = *scav.x.With(fields...)
 	scav.entryHolder = sc.entryHolder
 	scav.kvs = kvs
+	scav.fields = fields
+	scav.scope = &scope{parent: sc.scope}
+
+	if len(diags) > 0 {
+		sc.mu.Lock()
+		n := len(sc.entries)
+		for _, d := range diags {
+			sc.collectDiagnostic(d)
+		}
+		added := sc.batch(n)
+		sc.mu.Unlock()
+		sc.publish(added)
+	}
 	return scav
 }
 
 func (sc *Scavenger) LogLevelEnabled(level int) bool {
-	return true
+	return sc.level.Enabled(zapcore.Level(level))
+}
+
+// SetMinLevel changes the minimum enabled log level of sc and all the loggers sharing
+// its log messages.
+func (sc *Scavenger) SetMinLevel(level int) {
+	sc.level.SetLevel(zapcore.Level(level))
+}
+
+// MinLevel returns the minimum enabled log level of sc.
+func (sc *Scavenger) MinLevel() int {
+	return int(sc.level.Level())
 }
 
 func (sc *Scavenger) Debug(args ...any) {
+	if !sc.LogLevelEnabled(ZapDebugLevel) {
+		return
+	}
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
 	sc.buf.Reset()
 	sc.x.Debug(args...)
-	sc.collectEntry(LevelDebug)
+	added := sc.collectEntry(LevelDebug)
+	sc.mu.Unlock()
+	sc.publish(added)
 }
 
 func (sc *Scavenger) Info(args ...any) {
+	if !sc.LogLevelEnabled(ZapInfoLevel) {
+		return
+	}
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
 	sc.buf.Reset()
 	sc.x.Info(args...)
-	sc.collectEntry(LevelInfo)
+	added := sc.collectEntry(LevelInfo)
+	sc.mu.Unlock()
+	sc.publish(added)
 }
 
 func (sc *Scavenger) Warn(args ...any) {
+	if !sc.LogLevelEnabled(ZapWarnLevel) {
+		return
+	}
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
 	sc.buf.Reset()
 	sc.x.Warn(args...)
-	sc.collectEntry(LevelWarn)
+	added := sc.collectEntry(LevelWarn)
+	sc.mu.Unlock()
+	sc.publish(added)
 }
 
 func (sc *Scavenger) Error(args ...any) {
+	if !sc.LogLevelEnabled(ZapErrorLevel) {
+		return
+	}
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
 	sc.buf.Reset()
 	sc.x.Error(args...)
-	sc.collectEntry(LevelError)
+	added := sc.collectEntry(LevelError)
+	sc.mu.Unlock()
+	sc.publish(added)
 }
 
 func (sc *Scavenger) Debugf(format string, args ...any) {
+	if !sc.LogLevelEnabled(ZapDebugLevel) {
+		return
+	}
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
 	sc.buf.Reset()
 	sc.x.Debugf(format, args...)
-	sc.collectEntry(LevelDebug)
+	added := sc.collectEntry(LevelDebug)
+	sc.mu.Unlock()
+	sc.publish(added)
 }
 
 func (sc *Scavenger) Infof(format string, args ...any) {
+	if !sc.LogLevelEnabled(ZapInfoLevel) {
+		return
+	}
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
 	sc.buf.Reset()
 	sc.x.Infof(format, args...)
-	sc.collectEntry(LevelInfo)
+	added := sc.collectEntry(LevelInfo)
+	sc.mu.Unlock()
+	sc.publish(added)
 }
 
 func (sc *Scavenger) Warnf(format string, args ...any) {
+	if !sc.LogLevelEnabled(ZapWarnLevel) {
+		return
+	}
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
 	sc.buf.Reset()
 	sc.x.Warnf(format, args...)
-	sc.collectEntry(LevelWarn)
+	added := sc.collectEntry(LevelWarn)
+	sc.mu.Unlock()
+	sc.publish(added)
 }
 
 func (sc *Scavenger) Errorf(format string, args ...any) {
+	if !sc.LogLevelEnabled(ZapErrorLevel) {
+		return
+	}
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
 	sc.buf.Reset()
 	sc.x.Errorf(format, args...)
-	sc.collectEntry(LevelError)
+	added := sc.collectEntry(LevelError)
+	sc.mu.Unlock()
+	sc.publish(added)
 }
 
 func (sc *Scavenger) Debugw(msg string, keyVals ...any) {
+	if !sc.LogLevelEnabled(ZapDebugLevel) {
+		return
+	}
+	fields, diags := checkKeyVals(keyVals, sc.structuredErrors)
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
 	sc.buf.Reset()
-	sc.x.Debugw(msg, keyVals...)
-	sc.collectEntry(LevelDebug)
+	sc.x.Debugw(msg, fields...)
+	added := sc.collectEntry(LevelDebug, diags...)
+	sc.mu.Unlock()
+	sc.publish(added)
 }
 
 func (sc *Scavenger) Infow(msg string, keyVals ...any) {
+	if !sc.LogLevelEnabled(ZapInfoLevel) {
+		return
+	}
+	fields, diags := checkKeyVals(keyVals, sc.structuredErrors)
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
 	sc.buf.Reset()
-	sc.x.Infow(msg, keyVals...)
-	sc.collectEntry(LevelInfo)
+	sc.x.Infow(msg, fields...)
+	added := sc.collectEntry(LevelInfo, diags...)
+	sc.mu.Unlock()
+	sc.publish(added)
 }
 
 func (sc *Scavenger) Warnw(msg string, keyVals ...any) {
+	if !sc.LogLevelEnabled(ZapWarnLevel) {
+		return
+	}
+	fields, diags := checkKeyVals(keyVals, sc.structuredErrors)
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
 	sc.buf.Reset()
-	sc.x.Warnw(msg, keyVals...)
-	sc.collectEntry(LevelWarn)
+	sc.x.Warnw(msg, fields...)
+	added := sc.collectEntry(LevelWarn, diags...)
+	sc.mu.Unlock()
+	sc.publish(added)
 }
 
 func (sc *Scavenger) Errorw(msg string, keyVals ...any) {
+	if !sc.LogLevelEnabled(ZapErrorLevel) {
+		return
+	}
+	fields, diags := checkKeyVals(keyVals, sc.structuredErrors)
 	sc.mu.Lock()
-	defer sc.mu.Unlock()
 	sc.buf.Reset()
-	sc.x.Errorw(msg, keyVals...)
-	sc.collectEntry(LevelError)
+	sc.x.Errorw(msg, fields...)
+	added := sc.collectEntry(LevelError, diags...)
+	sc.mu.Unlock()
+	sc.publish(added)
 }
 
-var (
-	_oddNumberErrMsg    = []byte("Ignored key without a value.")
-	_nonStringKeyErrMsg = []byte("Ignored key-value pairs with non-string keys.")
-)
+type batch struct {
+	entries []LogEntry
+	subs    []*subscriber
+}
 
-func splitFirstLine(data []byte) ([]byte, []byte) {
-	if idx := bytes.Index(data, lineEnding); idx >= 0 {
-		next := idx + len(lineEnding)
-		return data[:idx], data[next:]
-	} else {
-		return data, nil
+// batch returns the entries collected since the n-th one. The caller must hold sc.mu.
+func (sc *Scavenger) batch(n int) batch {
+	return batch{
+		entries: sc.entries[n:len(sc.entries):len(sc.entries)],
+		subs:    sc.subs,
 	}
 }
 
-func (sc *Scavenger) collectEntry(level string) {
-	x1 := sc.buf.Bytes()
-	x2 := bytes.TrimSuffix(x1, lineEnding)
-
-	processFirstLine := func() {
-		first, rest := splitFirstLine(x2)
-		sc.entries = append(sc.entries, LogEntry{
-			Level:   LevelError,
-			Message: string(first),
-		})
-		x2 = rest
+func (sc *Scavenger) collectEntry(level string, diags ...diagnostic) batch {
+	n := len(sc.entries)
+	msg := string(bytes.TrimSuffix(sc.buf.Bytes(), lineEnding))
+	for _, d := range diags {
+		sc.collectDiagnostic(d)
 	}
+	sc.entries = append(sc.entries, LogEntry{
+		Level:   level,
+		Message: msg,
+		Scope:   sc.kvs,
+		scope:   sc.scope,
+	})
+	return sc.batch(n)
+}
 
-	for {
-		if bytes.HasPrefix(x2, _oddNumberErrMsg) {
-			processFirstLine()
-			continue
+func (sc *Scavenger) collectDiagnostic(d diagnostic) {
+	sc.buf.Reset()
+	sc.x.Desugar().Error(d.msg, d.field)
+	sc.entries = append(sc.entries, LogEntry{
+		Level:   LevelError,
+		Message: string(bytes.TrimSuffix(sc.buf.Bytes(), lineEnding)),
+		Scope:   sc.kvs,
+		Kind:    EntryDiagnostic,
+		Keys:    d.keys,
+		scope:   sc.scope,
+	})
+}
+
+// publish delivers the newly collected entries to the observers of sc.
+func (sc *Scavenger) publish(b batch) {
+	if tb := sc.tb; tb != nil {
+		for _, e := range b.entries {
+			tb.Log(e.String())
 		}
-		if bytes.HasPrefix(x2, _nonStringKeyErrMsg) {
-			processFirstLine()
-			continue
+	}
+	for _, sub := range b.subs {
+		for i := range b.entries {
+			sub.deliver(&b.entries[i])
 		}
-		break
 	}
-
-	sc.entries = append(sc.entries, LogEntry{
-		Level:   level,
-		Message: string(x2),
-	})
 }
 
 func (sc *Scavenger) FlushLogger() error {
 	return nil
 }
 
-// Reset clears all collected messages.
+// Reset clears all collected messages. Checkpoints taken before remain valid.
 func (sc *Scavenger) Reset() {
 	sc.mu.Lock()
+	sc.dropped += len(sc.entries)
 	sc.entries = nil
 	sc.mu.Unlock()
 }
 
+// view returns the log messages visible to sc. The caller must hold sc.mu.
+func (sc *Scavenger) view() []LogEntry {
+	entries := sc.entries
+	if start := sc.since - sc.dropped; start >= len(entries) {
+		return nil
+	} else if start > 0 {
+		entries = entries[start:]
+	}
+	if sc.filter == nil {
+		return entries
+	}
+
+	var ret []LogEntry
+	for i := range entries {
+		if sc.filter(&entries[i]) {
+			ret = append(ret, entries[i])
+		}
+	}
+	return ret
+}
+
+// Mark returns a Checkpoint referring to the current end of the collected messages.
+// The Checkpoint is shared by all the loggers created through NewLoggerWith.
+func (sc *Scavenger) Mark() Checkpoint {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return Checkpoint{
+		holder: sc.entryHolder,
+		n:      sc.dropped + len(sc.entries),
+	}
+}
+
+// Since returns a view of sc that contains only the log messages collected after cp.
+// The view keeps receiving new log messages, and indexes reported by the view are
+// relative to cp. Logging through the view is the same as logging through sc.
+func (sc *Scavenger) Since(cp Checkpoint) *Scavenger {
+	if cp.holder != nil && cp.holder != sc.entryHolder {
+		panic("the checkpoint belongs to another Scavenger")
+	}
+	scav := *sc
+	if cp.n > scav.since {
+		scav.since = cp.n
+	}
+	return &scav
+}
+
 // Finder returns a MessageFinder.
 func (sc *Scavenger) Finder() *MessageFinder {
 	return (*MessageFinder)(sc)
@@ -260,15 +501,16 @@ func (sc *Scavenger) Entries() []LogEntry {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
 
-	clone := make([]LogEntry, len(sc.entries))
-	copy(clone, sc.entries)
+	entries := sc.view()
+	clone := make([]LogEntry, len(entries))
+	copy(clone, entries)
 	return clone
 }
 
 // Len returns the number of the collected messages.
 func (sc *Scavenger) Len() int {
 	sc.mu.Lock()
-	n := len(sc.entries)
+	n := len(sc.view())
 	sc.mu.Unlock()
 	return n
 }
@@ -279,8 +521,9 @@ func (sc *Scavenger) Dump() string {
 	defer sc.mu.Unlock()
 
 	var sb strings.Builder
-	for _, e := range sc.entries {
-		_, _ = fmt.Fprintf(&sb, "%s\t%s\n", e.Level, e.Message)
+	for _, e := range sc.view() {
+		sb.WriteString(e.String())
+		sb.WriteByte('\n')
 	}
 	return sb.String()
 }
@@ -289,7 +532,7 @@ func (sc *Scavenger) Dump() string {
 func (sc *Scavenger) LogEntry(index int) LogEntry {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
-	return sc.entries[index]
+	return sc.view()[index]
 }
 
 // Filter creates a new Scavenger that contains only the log messages satisfying the predicate fn.
@@ -297,9 +540,10 @@ func (sc *Scavenger) Filter(fn func(level, msg string) bool) *Scavenger {
 	sc.mu.Lock()
 	defer sc.mu.Unlock()
 
-	scav := NewScavenger()
-	scav.entries = make([]LogEntry, 0, len(sc.entries))
-	for _, e := range sc.entries {
+	entries := sc.view()
+	scav := NewScavenger(WithMinLevel(sc.MinLevel()))
+	scav.entries = make([]LogEntry, 0, len(entries))
+	for _, e := range entries {
 		if fn == nil || fn(e.Level, e.Message) {
 			scav.entries = append(scav.entries, e)
 		}
@@ -316,3 +560,18 @@ func (sc *Scavenger) SequenceExists(seq []string) bool {
 	_, ok := sc.Finder().FindSequence(seq)
 	return ok
 }
+
+// AbsentBetween reports whether at least one range delimited by begin and end exists and
+// no log message matching str lies inside any of them. The indexes of the offending log
+// messages are returned as well. See MessageFinder.FindBetween for details.
+func (sc *Scavenger) AbsentBetween(begin, end, str string) ([]int, bool) {
+	ret, found := sc.Finder().FindBetween(begin, end, str)
+	return ret, found && len(ret) == 0
+}
+
+// AbsentAfter reports whether no log message matching str comes after index. The indexes
+// of the offending log messages are returned as well.
+func (sc *Scavenger) AbsentAfter(index int, str string) ([]int, bool) {
+	ret := sc.Finder().FindAfter(index, str)
+	return ret, len(ret) == 0
+}
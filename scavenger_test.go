@@ -2,6 +2,7 @@ package slog
 
 import (
 	"fmt"
+	"go.uber.org/zap"
 	"math"
 	"regexp"
 	"runtime/debug"
@@ -436,3 +437,243 @@ func TestScavenger_Multiline(t *testing.T) {
 		t.Fatal(`count != 3`)
 	}
 }
+
+func TestScavenger_AbsentBetween(t *testing.T) {
+	var sc = NewScavenger()
+	sc.Info("tx begin")
+	sc.Debug("select 1")
+	sc.Info("tx commit")
+	sc.Error("connection lost")
+	sc.Info("tx begin")
+	sc.Error("deadlock detected")
+	sc.Warn("retrying")
+	sc.Info("tx commit")
+	sc.Info("tx begin")
+	sc.Error("never committed")
+
+	if ret, ok := sc.AbsentBetween("tx begin", "tx commit", "select"); ok || len(ret) != 1 || ret[0] != 1 {
+		t.Fatal("AbsentBetween does not work as expected")
+	}
+	if ret, ok := sc.AbsentBetween("tx begin", "tx commit", "rex: ^conn"); !ok || len(ret) != 0 {
+		t.Fatal("AbsentBetween does not work as expected")
+	}
+	if _, ok := sc.AbsentBetween("tx begin", "tx rollback", "select"); ok {
+		t.Fatal("AbsentBetween does not work as expected")
+	}
+
+	ret, ok := sc.Finder().FindBetweenFunc("tx begin", "tx commit", func(level, msg string) bool {
+		return level == LevelError
+	})
+	if !ok || len(ret) != 1 || ret[0] != 5 {
+		t.Fatal("FindBetweenFunc does not work as expected")
+	}
+}
+
+func TestScavenger_AbsentAfter(t *testing.T) {
+	var sc = NewScavenger()
+	sc.Info("hello")
+	sc.Info("world")
+	sc.Error("hello again")
+
+	if ret, ok := sc.AbsentAfter(-1, "hello"); ok || len(ret) != 2 {
+		t.Fatal("AbsentAfter does not work as expected")
+	}
+	if ret, ok := sc.AbsentAfter(0, "rex: ^hello$"); !ok || len(ret) != 0 {
+		t.Fatal("AbsentAfter does not work as expected")
+	}
+	if ret, ok := sc.AbsentAfter(1, "hello"); ok || len(ret) != 1 || ret[0] != 2 {
+		t.Fatal("AbsentAfter does not work as expected")
+	}
+	if ret := sc.Finder().FindAfterFunc(-5, func(level, msg string) bool {
+		return level == LevelError
+	}); len(ret) != 1 || ret[0] != 2 {
+		t.Fatal("FindAfterFunc does not work as expected")
+	}
+}
+
+func TestScavenger_Since(t *testing.T) {
+	var sc = NewScavenger()
+	child := sc.NewLoggerWith("phase", 1).(*Scavenger)
+	sc.Info("setup")
+	child.Info("hello")
+
+	cp := child.Mark()
+	v := sc.Since(cp)
+	if v.Len() != 0 || v.Exists("hello") {
+		t.Fatal("Since does not work as expected")
+	}
+
+	child.Info("world")
+	sc.Warn("hello again")
+	if v.Len() != 2 || sc.Len() != 4 {
+		t.Fatal("Since does not work as expected")
+	}
+	if !v.SequenceExists([]string{"world", "hello"}) {
+		t.Fatal("Since does not work as expected")
+	}
+	if ret := v.Finder().Find("hello"); len(ret) != 1 || ret[0] != 1 {
+		t.Fatal("Since does not work as expected")
+	}
+	dump := `INFO	world	{"phase": 1}
+WARN	hello again
+`
+	if v.Dump() != dump {
+		t.Fatal("something is wrong with Dump: " + v.Dump())
+	}
+
+	sc.Reset()
+	sc.Info("after reset")
+	if v.Len() != 1 || v.LogEntry(0).Message != "after reset" {
+		t.Fatal("Since does not work as expected after Reset")
+	}
+
+	cp2 := sc.Mark()
+	if sc.Since(cp2).Since(cp).Len() != 0 {
+		t.Fatal("Since does not work as expected")
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Fatal("Since should panic")
+		}
+	}()
+	NewScavenger().Since(cp)
+}
+
+func TestScavenger_ScopeView(t *testing.T) {
+	var sc = NewScavenger()
+	h1 := sc.NewLoggerWith("handler", "UpdateUserName").(*Scavenger)
+	h2 := sc.NewLoggerWith("handler", "DeleteUser").(*Scavenger)
+	h1x := h1.NewLoggerWith(zap.Int("uid", 100)).(*Scavenger)
+	sc.Info("starting")
+	h1.Info("invalid user name")
+	h2.Info("user deleted")
+	h1x.Warn("retrying")
+
+	if v := h1.ScopeView(); v.Len() != 2 || !v.SequenceExists([]string{"invalid user name", "retrying"}) {
+		t.Fatal("ScopeView does not work as expected")
+	}
+	if v := h2.ScopeView(); v.Len() != 1 || v.LogEntry(0).Scope[1] != "DeleteUser" {
+		t.Fatal("ScopeView does not work as expected")
+	}
+	if sc.ScopeView().Len() != 4 {
+		t.Fatal("ScopeView does not work as expected")
+	}
+
+	v := sc.ScopeViewFunc(func(key string, val any) bool {
+		return key == "uid" && val == int64(100)
+	})
+	if v.Len() != 1 || !v.Exists("retrying") {
+		t.Fatal("ScopeViewFunc does not work as expected")
+	}
+
+	cp := sc.Mark()
+	h1x.Info("done")
+	h2.Info("done")
+	if n := h1.ScopeView().Since(cp).Len(); n != 1 {
+		t.Fatal("ScopeView does not work with Since")
+	}
+}
+
+func TestScavenger_MinLevel(t *testing.T) {
+	var sc = NewScavenger(WithMinLevel(ZapInfoLevel))
+	child := sc.NewLoggerWith("foo", "bar")
+	if sc.LogLevelEnabled(ZapDebugLevel) || child.LogLevelEnabled(ZapDebugLevel) {
+		t.Fatal("LogLevelEnabled does not work as expected")
+	}
+	if !sc.LogLevelEnabled(ZapInfoLevel) || !child.LogLevelEnabled(ZapErrorLevel) {
+		t.Fatal("LogLevelEnabled does not work as expected")
+	}
+
+	var expensive int
+	if sc.LogLevelEnabled(ZapDebugLevel) {
+		expensive++
+	}
+	sc.Debug("1")
+	sc.Debugf("%d", 2)
+	child.Debugw("3")
+	sc.Info("4")
+	if expensive != 0 || sc.Len() != 1 {
+		t.Fatal("debug messages should be skipped")
+	}
+
+	sc.SetMinLevel(ZapDebugLevel)
+	child.Debugw("5")
+	if !child.LogLevelEnabled(ZapDebugLevel) || sc.Len() != 2 {
+		t.Fatal("SetMinLevel does not work as expected")
+	}
+
+	sc.SetMinLevel(ZapErrorLevel)
+	sc.Warn("6")
+	sc.Error("7")
+	if sc.MinLevel() != ZapErrorLevel || sc.Dump() != "INFO\t4\nDEBUG\t5\t{\"foo\": \"bar\"}\nERROR\t7\n" {
+		t.Fatal("SetMinLevel does not work as expected")
+	}
+	if sc.Filter(nil).LogLevelEnabled(ZapWarnLevel) {
+		t.Fatal("Filter should inherit the minimum level")
+	}
+}
+
+var _ TB = (*testing.T)(nil)
+
+type fakeTB struct {
+	logs     []string
+	cleanups []func()
+	failed   bool
+}
+
+func (tb *fakeTB) Log(args ...any) {
+	tb.logs = append(tb.logs, fmt.Sprint(args...))
+}
+
+func (tb *fakeTB) Cleanup(fn func()) {
+	tb.cleanups = append(tb.cleanups, fn)
+}
+
+func (tb *fakeTB) Failed() bool {
+	return tb.failed
+}
+
+func TestScavenger_WithTestLog(t *testing.T) {
+	var tb fakeTB
+	var sc = NewScavenger(WithTestLog(&tb))
+	sc.Info("hello")
+	sc.NewLoggerWith("foo", 1).Warnw("world", "bar", 2)
+	sc.Infow("odd", "key")
+	if len(tb.logs) != 4 {
+		t.Fatal(`len(tb.logs) != 4`)
+	}
+	if tb.logs[0] != "INFO\thello" || tb.logs[1] != `WARN	world	{"foo": 1, "bar": 2}` {
+		t.Fatal("WithTestLog does not work as expected")
+	}
+	if !strings.HasPrefix(tb.logs[2], "ERROR\tIgnored key without a value.") || tb.logs[3] != "INFO\todd" {
+		t.Fatal("WithTestLog does not work as expected")
+	}
+	if len(tb.cleanups) != 0 {
+		t.Fatal(`len(tb.cleanups) != 0`)
+	}
+}
+
+func TestScavenger_WithTestLogOnFailure(t *testing.T) {
+	for _, failed := range []bool{false, true} {
+		var tb fakeTB
+		var sc = NewScavenger(WithTestLogOnFailure(&tb))
+		sc.Info("hello")
+		sc.Error("world")
+		if len(tb.logs) != 0 || len(tb.cleanups) != 1 {
+			t.Fatal("WithTestLogOnFailure does not work as expected")
+		}
+
+		tb.failed = failed
+		tb.cleanups[0]()
+		if !failed {
+			if len(tb.logs) != 0 {
+				t.Fatal("WithTestLogOnFailure does not work as expected")
+			}
+		} else {
+			if len(tb.logs) != 1 || !strings.HasSuffix(tb.logs[0], "\nINFO\thello\nERROR\tworld\n") {
+				t.Fatal("WithTestLogOnFailure does not work as expected")
+			}
+		}
+	}
+}
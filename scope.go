@@ -0,0 +1,87 @@
+package slog
+
+import (
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+// scope identifies a Scavenger created through NewLoggerWith.
+type scope struct {
+	parent *scope
+}
+
+func (s *scope) contains(x *scope) bool {
+	for ; x != nil; x = x.parent {
+		if x == s {
+			return true
+		}
+	}
+	return false
+}
+
+// rangeFields calls fn for each field in keyVals, which are treated as they are in
+// NewLoggerWith. Malformed pairs are skipped. It stops as soon as fn returns false.
+func rangeFields(keyVals []any, fn func(key string, val any) bool) {
+	for i := 0; i < len(keyVals); {
+		switch x := keyVals[i].(type) {
+		case zap.Field:
+			enc := zapcore.NewMapObjectEncoder()
+			x.AddTo(enc)
+			if !fn(x.Key, enc.Fields[x.Key]) {
+				return
+			}
+			i++
+			continue
+		case error:
+			if !fn("error", x) {
+				return
+			}
+			i++
+			continue
+		}
+		if i == len(keyVals)-1 {
+			return
+		}
+		if key, ok := keyVals[i].(string); ok {
+			if !fn(key, keyVals[i+1]) {
+				return
+			}
+		}
+		i += 2
+	}
+}
+
+func (sc *Scavenger) withFilter(fn func(e *LogEntry) bool) *Scavenger {
+	scav := *sc
+	if prev := sc.filter; prev != nil {
+		scav.filter = func(e *LogEntry) bool {
+			return prev(e) && fn(e)
+		}
+	} else {
+		scav.filter = fn
+	}
+	return &scav
+}
+
+// ScopeView returns a view of sc that contains only the log messages logged by sc and
+// the loggers derived from it through NewLoggerWith. The view keeps receiving new log
+// messages.
+func (sc *Scavenger) ScopeView() *Scavenger {
+	s := sc.scope
+	return sc.withFilter(func(e *LogEntry) bool {
+		return s.contains(e.scope)
+	})
+}
+
+// ScopeViewFunc returns a view of sc that contains only the log messages whose scope has
+// at least one field satisfying the predicate fn. The view keeps receiving new log messages.
+func (sc *Scavenger) ScopeViewFunc(fn func(key string, val any) bool) *Scavenger {
+	return sc.withFilter(func(e *LogEntry) bool {
+		var found bool
+		rangeFields(e.Scope, func(key string, val any) bool {
+			found = fn(key, val)
+			return !found
+		})
+		return found
+	})
+}
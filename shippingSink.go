@@ -0,0 +1,440 @@
+package slog
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	defaultShippingTimeout      = 5 * time.Second
+	defaultShippingFlushTimeout = 5 * time.Second
+	defaultShippingBatchSize    = 100
+	defaultShippingMaxMemory    = 1 << 20
+	defaultShippingMaxSpoolSize = 100 << 20
+	shippingSegmentSize         = 4 << 20
+	shippingMinBackoff          = 100 * time.Millisecond
+	shippingMaxBackoff          = 5 * time.Second
+)
+
+var (
+	_ zap.Sink = &shippingSink{}
+)
+
+var (
+	errShippingSinkClosed = errors.New("the shipping sink is closed")
+)
+
+// rejectedError is returned by a transport when the collector rejects a batch for good,
+// so that sending it again is pointless.
+type rejectedError struct {
+	status string
+}
+
+func (e *rejectedError) Error() string {
+	return "the collector rejected the log entries: " + e.status
+}
+
+type shippingTransport interface {
+	send(batch [][]byte) error
+	close() error
+}
+
+// shippingSink ships encoded entries to a collector in the background. Entries wait
+// in memory, or in a spool directory when the collector is unreachable or too slow,
+// and are sent in order. Over HTTP, a batch is delivered once the collector responds
+// 2xx, so delivery is at least once. A batch rejected with a 4xx status other than
+// 408 and 429 is dropped rather than retried, and reported by Sync. Over TCP, there
+// is no acknowledgement: a batch counts as delivered once it is written to the
+// connection, so the entries in flight are lost if the connection or the collector
+// fails.
+type shippingSink struct {
+	transport    shippingTransport
+	batchSize    int
+	maxMemory    int
+	flushTimeout time.Duration
+
+	mu       sync.Mutex
+	mem      [][]byte
+	memBytes int
+	spool    *spool
+	inflight int
+	failing  bool
+	closed   bool
+	// rejected is the error of the last batch dropped because the collector rejected
+	// it, which is reported by the next call to Sync.
+	rejected error
+	// drained is closed when nothing is left to deliver.
+	drained   chan struct{}
+	isDrained bool
+
+	wake chan struct{}
+	stop chan struct{}
+	done chan struct{}
+}
+
+// newShippingSink creates a sink from a URL like
+// ship+tcp://collector:5170?spool=/var/spool/app or
+// ship+https://collector/ingest?spool=/var/spool/app. Over TCP, entries are written
+// as they are, so a line-oriented encoding should be used, and delivery is best
+// effort. Over HTTP, each batch of entries is POSTed as the body of a request. The
+// query parameters are:
+//
+//	spool           the spool directory, which must not be shared with another sink or
+//	                process. By default, it is derived from the URL under
+//	                os.TempDir(), so the entries left in it are replayed by the next
+//	                sink created with the same URL, e.g. after a restart
+//	max_spool_size  the maximum size of the spool in bytes, 100 MiB by default.
+//	                Entries that do not fit are dropped
+//	max_memory      the maximum size in bytes of the entries waiting in memory before
+//	                they are moved to the spool, 1 MiB by default
+//	batch_size      the maximum number of entries sent at once, 100 by default
+//	timeout         the timeout of connecting and sending, 5s by default
+//	flush_timeout   how long Sync, and therefore FlushLogger, waits for delivery,
+//	                5s by default
+//
+// The other query parameters are kept in the URL of HTTP requests.
+func newShippingSink(u *url.URL) (zap.Sink, error) {
+	q := u.Query()
+	param := func(name string) string {
+		v := q.Get(name)
+		q.Del(name)
+		return v
+	}
+	parseInt := func(name string, def int64) (int64, error) {
+		v := param(name)
+		if v == "" {
+			return def, nil
+		}
+		n, err := strconv.ParseInt(v, 10, 64)
+		if err != nil || n <= 0 {
+			return 0, fmt.Errorf("invalid %s of the %s sink: %q", name, u.Scheme, v)
+		}
+		return n, nil
+	}
+	parseDuration := func(name string, def time.Duration) (time.Duration, error) {
+		v := param(name)
+		if v == "" {
+			return def, nil
+		}
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			return 0, fmt.Errorf("invalid %s of the %s sink: %q", name, u.Scheme, v)
+		}
+		return d, nil
+	}
+
+	dir := param("spool")
+	maxSpoolSize, err := parseInt("max_spool_size", defaultShippingMaxSpoolSize)
+	if err != nil {
+		return nil, err
+	}
+	maxMemory, err := parseInt("max_memory", defaultShippingMaxMemory)
+	if err != nil {
+		return nil, err
+	}
+	batchSize, err := parseInt("batch_size", defaultShippingBatchSize)
+	if err != nil {
+		return nil, err
+	}
+	timeout, err := parseDuration("timeout", defaultShippingTimeout)
+	if err != nil {
+		return nil, err
+	}
+	flushTimeout, err := parseDuration("flush_timeout", defaultShippingFlushTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	var transport shippingTransport
+	switch u.Scheme {
+	case "ship+tcp":
+		transport = &tcpTransport{addr: u.Host, timeout: timeout}
+	case "ship+http", "ship+https":
+		target := *u
+		target.Scheme = strings.TrimPrefix(u.Scheme, "ship+")
+		target.RawQuery = q.Encode()
+		transport = &httpTransport{url: target.String(), client: &http.Client{Timeout: timeout}}
+	default:
+		return nil, fmt.Errorf("unknown scheme of the shipping sink: %q", u.Scheme)
+	}
+
+	if dir == "" {
+		name := strings.NewReplacer(":", "_", "/", "_").Replace(u.Scheme + "_" + u.Host + u.Path)
+		dir = filepath.Join(os.TempDir(), "slog-spool", name)
+	}
+	sp, err := openSpool(dir, maxSpoolSize, shippingSegmentSize)
+	if err != nil {
+		return nil, err
+	}
+	s := &shippingSink{
+		transport:    transport,
+		batchSize:    int(batchSize),
+		maxMemory:    int(maxMemory),
+		flushTimeout: flushTimeout,
+		spool:        sp,
+		drained:      make(chan struct{}),
+		wake:         make(chan struct{}, 1),
+		stop:         make(chan struct{}),
+		done:         make(chan struct{}),
+	}
+	go s.run()
+	s.notify()
+	return s, nil
+}
+
+func (s *shippingSink) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *shippingSink) Write(p []byte) (n int, err error) {
+	rec := make([]byte, len(p))
+	copy(rec, p)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return 0, errShippingSinkClosed
+	}
+	if s.isDrained {
+		s.drained = make(chan struct{})
+		s.isDrained = false
+	}
+	s.mem = append(s.mem, rec)
+	s.memBytes += len(rec)
+	if s.failing || s.memBytes > s.maxMemory {
+		if err := s.spill(); err != nil {
+			return 0, err
+		}
+	}
+	s.notify()
+	return len(p), nil
+}
+
+// spill moves the entries waiting in memory to the tail of the spool. The entries
+// that do not fit are dropped.
+func (s *shippingSink) spill() error {
+	err := s.spool.append(s.mem)
+	s.mem, s.memBytes = nil, 0
+	return err
+}
+
+func (s *shippingSink) pending() int {
+	n := len(s.mem) + s.inflight
+	if !s.spool.empty() {
+		n++
+	}
+	return n
+}
+
+// next returns the oldest entries. The spool always holds older entries than memory.
+func (s *shippingSink) next() (batch [][]byte, fromSpool bool) {
+	if batch = s.spool.peek(s.batchSize); len(batch) > 0 {
+		return batch, true
+	}
+	n := len(s.mem)
+	if n > s.batchSize {
+		n = s.batchSize
+	}
+	batch = s.mem[:n:n]
+	s.mem = s.mem[n:]
+	for _, rec := range batch {
+		s.memBytes -= len(rec)
+	}
+	return batch, false
+}
+
+func (s *shippingSink) run() {
+	defer close(s.done)
+	backoff := shippingMinBackoff
+	for {
+		s.mu.Lock()
+		batch, fromSpool := s.next()
+		if len(batch) == 0 {
+			if !s.isDrained {
+				close(s.drained)
+				s.isDrained = true
+			}
+			s.mu.Unlock()
+			select {
+			case <-s.wake:
+				continue
+			case <-s.stop:
+				return
+			}
+		}
+		s.inflight = len(batch)
+		s.mu.Unlock()
+
+		for {
+			err := s.transport.send(batch)
+			if err == nil {
+				break
+			}
+			var rejected *rejectedError
+			if errors.As(err, &rejected) {
+				s.mu.Lock()
+				s.rejected = err
+				s.mu.Unlock()
+				break
+			}
+			s.mu.Lock()
+			s.failing = true
+			_ = s.spill()
+			s.mu.Unlock()
+			select {
+			case <-time.After(backoff):
+				if backoff *= 2; backoff > shippingMaxBackoff {
+					backoff = shippingMaxBackoff
+				}
+			case <-s.stop:
+				if !fromSpool {
+					s.mu.Lock()
+					_ = s.spool.prepend(batch)
+					s.mu.Unlock()
+				}
+				return
+			}
+		}
+
+		s.mu.Lock()
+		if fromSpool {
+			s.spool.commit(batch)
+		}
+		s.inflight = 0
+		s.failing = false
+		s.mu.Unlock()
+		backoff = shippingMinBackoff
+	}
+}
+
+// Sync waits until every entry has been delivered, or until flush_timeout elapses, in
+// which case the entries still in memory are moved to the spool. It also reports the
+// last batch rejected by the collector since the previous call.
+func (s *shippingSink) Sync() error {
+	s.mu.Lock()
+	if s.pending() == 0 {
+		defer s.mu.Unlock()
+		return s.takeRejected()
+	}
+	drained := s.drained
+	s.mu.Unlock()
+
+	timer := time.NewTimer(s.flushTimeout)
+	defer timer.Stop()
+	select {
+	case <-drained:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return s.takeRejected()
+	case <-timer.C:
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		if err := s.spill(); err != nil {
+			return err
+		}
+		return fmt.Errorf("the log entries are not delivered within %s", s.flushTimeout)
+	}
+}
+
+func (s *shippingSink) takeRejected() error {
+	err := s.rejected
+	s.rejected = nil
+	return err
+}
+
+func (s *shippingSink) Close() error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return nil
+	}
+	s.closed = true
+	s.mu.Unlock()
+
+	close(s.stop)
+	<-s.done
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	err := s.spill()
+	if e := s.spool.close(); err == nil {
+		err = e
+	}
+	if e := s.transport.close(); err == nil {
+		err = e
+	}
+	return err
+}
+
+type tcpTransport struct {
+	addr    string
+	timeout time.Duration
+	conn    net.Conn
+}
+
+func (t *tcpTransport) send(batch [][]byte) error {
+	if t.conn == nil {
+		conn, err := net.DialTimeout("tcp", t.addr, t.timeout)
+		if err != nil {
+			return err
+		}
+		t.conn = conn
+	}
+	_ = t.conn.SetWriteDeadline(time.Now().Add(t.timeout))
+	if _, err := t.conn.Write(bytes.Join(batch, nil)); err != nil {
+		_ = t.conn.Close()
+		t.conn = nil
+		return err
+	}
+	return nil
+}
+
+func (t *tcpTransport) close() error {
+	if t.conn == nil {
+		return nil
+	}
+	return t.conn.Close()
+}
+
+type httpTransport struct {
+	url    string
+	client *http.Client
+}
+
+func (t *httpTransport) send(batch [][]byte) error {
+	resp, err := t.client.Post(t.url, "application/x-ndjson", bytes.NewReader(bytes.Join(batch, nil)))
+	if err != nil {
+		return err
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	switch code := resp.StatusCode; {
+	case code >= 200 && code <= 299:
+		return nil
+	case code >= 400 && code <= 499 && code != http.StatusRequestTimeout && code != http.StatusTooManyRequests:
+		// The batch is dropped, as the collector would reject it again.
+		return &rejectedError{status: resp.Status}
+	default:
+		return fmt.Errorf("the collector responded %s", resp.Status)
+	}
+}
+
+func (t *httpTransport) close() error {
+	t.client.CloseIdleConnections()
+	return nil
+}
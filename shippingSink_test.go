@@ -0,0 +1,257 @@
+package slog
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func openShippingSink(t *testing.T, rawURL string) *shippingSink {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sink, err := newShippingSink(u)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = sink.Close() })
+	return sink.(*shippingSink)
+}
+
+func spoolFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	files, err := filepath.Glob(filepath.Join(dir, "*"+spoolExt))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return files
+}
+
+type collector struct {
+	mu         sync.Mutex
+	lines      []string
+	fail       int
+	failStatus int
+}
+
+func (c *collector) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	data, _ := io.ReadAll(r.Body)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.fail > 0 {
+		c.fail--
+		if c.failStatus != 0 {
+			w.WriteHeader(c.failStatus)
+		} else {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		}
+		return
+	}
+	c.lines = append(c.lines, strings.Split(strings.TrimSuffix(string(data), "\n"), "\n")...)
+}
+
+func (c *collector) received() []string {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.lines...)
+}
+
+func expectLines(t *testing.T, lines []string, n int) {
+	t.Helper()
+	if len(lines) != n {
+		t.Fatalf("%d lines are expected, got %d: %v", n, len(lines), lines)
+	}
+	for i, line := range lines {
+		if line != fmt.Sprint("line ", i) {
+			t.Fatalf("the lines are not delivered in order: %v", lines)
+		}
+	}
+}
+
+func TestShippingSink_HTTP(t *testing.T) {
+	c := &collector{fail: 2}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	dir := t.TempDir()
+	sink := openShippingSink(t, "ship+"+srv.URL+"/ingest?spool="+dir+"&batch_size=3&flush_timeout=10s")
+	for i := 0; i < 10; i++ {
+		if _, err := fmt.Fprintf(sink, "line %d\n", i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := sink.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	expectLines(t, c.received(), 10)
+	if len(spoolFiles(t, dir)) != 0 {
+		t.Fatal("the spool should be empty after delivery")
+	}
+}
+
+func TestShippingSink_Rejected(t *testing.T) {
+	c := &collector{fail: 1, failStatus: http.StatusBadRequest}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	sink := openShippingSink(t, "ship+"+srv.URL+"/ingest?spool="+t.TempDir()+"&flush_timeout=10s")
+	_, _ = fmt.Fprintf(sink, "rejected\n")
+	if err := sink.Sync(); err == nil || !strings.Contains(err.Error(), "400") {
+		t.Fatalf("Sync should report the rejected entries. err: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		_, _ = fmt.Fprintf(sink, "line %d\n", i)
+	}
+	if err := sink.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	expectLines(t, c.received(), 3)
+}
+
+func TestShippingSink_Replay(t *testing.T) {
+	dir := t.TempDir()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := ln.Addr().String()
+	_ = ln.Close()
+
+	sink := openShippingSink(t, "ship+tcp://"+addr+"?spool="+dir+"&flush_timeout=200ms")
+	for i := 0; i < 5; i++ {
+		_, _ = fmt.Fprintf(sink, "line %d\n", i)
+	}
+	if err := sink.Sync(); err == nil {
+		t.Fatal("Sync should fail when the collector is unreachable")
+	}
+	if err := sink.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(spoolFiles(t, dir)) == 0 {
+		t.Fatal("the entries should be spooled")
+	}
+
+	ln, err = net.Listen("tcp", addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+	lines := make(chan string, 100)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+		sc := bufio.NewScanner(conn)
+		for sc.Scan() {
+			lines <- sc.Text()
+		}
+	}()
+
+	sink = openShippingSink(t, "ship+tcp://"+addr+"?spool="+dir+"&flush_timeout=10s")
+	for i := 5; i < 8; i++ {
+		_, _ = fmt.Fprintf(sink, "line %d\n", i)
+	}
+	if err := sink.Sync(); err != nil {
+		t.Fatal(err)
+	}
+	var received []string
+	for len(received) < 8 {
+		select {
+		case line := <-lines:
+			received = append(received, line)
+		case <-time.After(5 * time.Second):
+			t.Fatalf("timeout. received: %v", received)
+		}
+	}
+	expectLines(t, received, 8)
+}
+
+func TestShippingSink_MaxSpoolSize(t *testing.T) {
+	dir := t.TempDir()
+	sink := openShippingSink(t, "ship+http://127.0.0.1:1/?spool="+dir+"&max_spool_size=100&max_memory=1&timeout=100ms")
+	var err error
+	for i := 0; i < 20 && err == nil; i++ {
+		_, err = fmt.Fprintf(sink, "line %d\n", i)
+	}
+	if err != errSpoolFull {
+		t.Fatalf("errSpoolFull is expected. err: %v", err)
+	}
+	var size int64
+	for _, f := range spoolFiles(t, dir) {
+		info, err := os.Stat(f)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size += info.Size()
+	}
+	if size > 100 {
+		t.Fatalf("the spool exceeds its maximum size: %d", size)
+	}
+}
+
+func TestShippingSink_SpoolDir(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	const rawURL = "ship+http://127.0.0.1:1/ingest?max_memory=1&timeout=100ms"
+	s1 := openShippingSink(t, rawURL)
+	u, _ := url.Parse(rawURL)
+	if _, err := newShippingSink(u); err == nil {
+		t.Fatal("a default spool directory in use should be rejected")
+	}
+	s2 := openShippingSink(t, "ship+http://127.0.0.1:1/other")
+	if s1.spool.dir == s2.spool.dir {
+		t.Fatal("the sinks of different URLs should not share a default spool directory")
+	}
+	_, _ = s1.Write([]byte("hello\n"))
+	if err := s1.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if len(spoolFiles(t, s1.spool.dir)) == 0 {
+		t.Fatal("the entries should be spooled")
+	}
+
+	s3 := openShippingSink(t, rawURL)
+	s3.mu.Lock()
+	replayed := !s3.spool.empty()
+	s3.mu.Unlock()
+	if s3.spool.dir != s1.spool.dir || !replayed {
+		t.Fatal("the default spool directory should be replayed by the next sink of the URL")
+	}
+
+	dir := t.TempDir()
+	openShippingSink(t, "ship+http://127.0.0.1:1/ingest?spool="+dir)
+	u, _ = url.Parse("ship+http://127.0.0.1:1/other?spool=" + dir)
+	if _, err := newShippingSink(u); err == nil {
+		t.Fatal("a spool directory in use should be rejected")
+	}
+}
+
+func TestConfig_Shipping(t *testing.T) {
+	c := &collector{}
+	srv := httptest.NewServer(c)
+	defer srv.Close()
+
+	cfg := NewProductionConfig()
+	cfg.OutputPaths = []string{"ship+" + srv.URL + "?spool=" + t.TempDir()}
+	logger := cfg.MustBuild()
+	logger.Info("hello")
+	if err := logger.FlushLogger(); err != nil {
+		t.Fatal(err)
+	}
+	if lines := c.received(); len(lines) != 1 || !strings.Contains(lines[0], `"msg":"hello"`) {
+		t.Fatalf("Config does not work as expected with the shipping sink: %v", lines)
+	}
+}
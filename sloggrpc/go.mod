@@ -0,0 +1,17 @@
+module github.com/edwingeng/slog/sloggrpc
+
+go 1.21
+
+require (
+	github.com/edwingeng/slog v0.1.0
+	go.uber.org/zap v1.27.0
+	google.golang.org/grpc v1.67.1
+)
+
+require (
+	go.uber.org/multierr v1.11.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	golang.org/x/term v0.25.0 // indirect
+)
+
+replace github.com/edwingeng/slog => ../
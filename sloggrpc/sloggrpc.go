@@ -0,0 +1,166 @@
+// Package sloggrpc implements grpclog.LoggerV2 on top of slog.Logger, so that the
+// internal logs of gRPC can be written to a ZapLogger or a Scavenger.
+//
+//	grpclog.SetLoggerV2(sloggrpc.NewLoggerV2(logger))
+package sloggrpc
+
+import (
+	"fmt"
+	"github.com/edwingeng/slog"
+	"google.golang.org/grpc/grpclog"
+	"os"
+	"strings"
+)
+
+var (
+	_ grpclog.LoggerV2      = &LoggerV2{}
+	_ grpclog.DepthLoggerV2 = &LoggerV2{}
+)
+
+// callerSkip makes a *slog.ZapLogger report the caller of the grpclog function, e.g.
+// grpclog.Info, by skipping LoggerV2.output, the method of LoggerV2 and the grpclog
+// function.
+const callerSkip = 3
+
+var osExit = os.Exit
+
+// Option configures a LoggerV2.
+type Option func(lv *LoggerV2)
+
+// WithVerbosity sets the verbosity of a LoggerV2. V(l) reports true only if l is not
+// greater than the verbosity. The default verbosity is 0.
+func WithVerbosity(v int) Option {
+	return func(lv *LoggerV2) {
+		lv.verbosity = v
+	}
+}
+
+// WithInfoLevel sets the slog level at which the Info family of a LoggerV2 logs, e.g.
+// slog.ZapDebugLevel. The default level is slog.ZapInfoLevel.
+func WithInfoLevel(level int) Option {
+	return func(lv *LoggerV2) {
+		lv.infoLevel = level
+	}
+}
+
+// LoggerV2 is a grpclog.LoggerV2 that writes to a slog.Logger. Warning is mapped to
+// Warn, and Fatal is mapped to Error, after which the program exits. It also
+// implements grpclog.DepthLoggerV2, so that a *slog.ZapLogger reports the callers of
+// the gRPC components rather than the grpclog package.
+type LoggerV2 struct {
+	l         slog.Logger
+	verbosity int
+	infoLevel int
+}
+
+// NewLoggerV2 creates a new LoggerV2 that writes to l.
+func NewLoggerV2(l slog.Logger, opts ...Option) *LoggerV2 {
+	lv := &LoggerV2{
+		l:         slog.AddCallerSkip(l, callerSkip),
+		infoLevel: slog.ZapInfoLevel,
+	}
+	for _, opt := range opts {
+		opt(lv)
+	}
+	return lv
+}
+
+func sprintln(args []any) string {
+	return strings.TrimSuffix(fmt.Sprintln(args...), "\n")
+}
+
+// output logs msg at level. depth is the number of frames between the caller of the
+// grpclog function and the caller of output, which is 0 for the methods of LoggerV2.
+func (lv *LoggerV2) output(depth int, level int, msg string) {
+	l := lv.l
+	if depth > 0 {
+		l = slog.AddCallerSkip(l, depth)
+	}
+	switch {
+	case level <= slog.ZapDebugLevel:
+		l.Debug(msg)
+	case level == slog.ZapInfoLevel:
+		l.Info(msg)
+	case level == slog.ZapWarnLevel:
+		l.Warn(msg)
+	default:
+		l.Error(msg)
+	}
+}
+
+func (lv *LoggerV2) Info(args ...any) {
+	lv.output(0, lv.infoLevel, fmt.Sprint(args...))
+}
+
+func (lv *LoggerV2) Infoln(args ...any) {
+	lv.output(0, lv.infoLevel, sprintln(args))
+}
+
+func (lv *LoggerV2) Infof(format string, args ...any) {
+	lv.output(0, lv.infoLevel, fmt.Sprintf(format, args...))
+}
+
+func (lv *LoggerV2) InfoDepth(depth int, args ...any) {
+	lv.output(depth, lv.infoLevel, sprintln(args))
+}
+
+func (lv *LoggerV2) Warning(args ...any) {
+	lv.output(0, slog.ZapWarnLevel, fmt.Sprint(args...))
+}
+
+func (lv *LoggerV2) Warningln(args ...any) {
+	lv.output(0, slog.ZapWarnLevel, sprintln(args))
+}
+
+func (lv *LoggerV2) Warningf(format string, args ...any) {
+	lv.output(0, slog.ZapWarnLevel, fmt.Sprintf(format, args...))
+}
+
+func (lv *LoggerV2) WarningDepth(depth int, args ...any) {
+	lv.output(depth, slog.ZapWarnLevel, sprintln(args))
+}
+
+func (lv *LoggerV2) Error(args ...any) {
+	lv.output(0, slog.ZapErrorLevel, fmt.Sprint(args...))
+}
+
+func (lv *LoggerV2) Errorln(args ...any) {
+	lv.output(0, slog.ZapErrorLevel, sprintln(args))
+}
+
+func (lv *LoggerV2) Errorf(format string, args ...any) {
+	lv.output(0, slog.ZapErrorLevel, fmt.Sprintf(format, args...))
+}
+
+func (lv *LoggerV2) ErrorDepth(depth int, args ...any) {
+	lv.output(depth, slog.ZapErrorLevel, sprintln(args))
+}
+
+func (lv *LoggerV2) exit() {
+	_ = lv.l.FlushLogger()
+	osExit(1)
+}
+
+func (lv *LoggerV2) Fatal(args ...any) {
+	lv.output(0, slog.ZapErrorLevel, fmt.Sprint(args...))
+	lv.exit()
+}
+
+func (lv *LoggerV2) Fatalln(args ...any) {
+	lv.output(0, slog.ZapErrorLevel, sprintln(args))
+	lv.exit()
+}
+
+func (lv *LoggerV2) Fatalf(format string, args ...any) {
+	lv.output(0, slog.ZapErrorLevel, fmt.Sprintf(format, args...))
+	lv.exit()
+}
+
+func (lv *LoggerV2) FatalDepth(depth int, args ...any) {
+	lv.output(depth, slog.ZapErrorLevel, sprintln(args))
+	lv.exit()
+}
+
+func (lv *LoggerV2) V(l int) bool {
+	return l <= lv.verbosity && lv.l.LogLevelEnabled(lv.infoLevel)
+}
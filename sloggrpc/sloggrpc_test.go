@@ -0,0 +1,102 @@
+package sloggrpc
+
+import (
+	"bytes"
+	"github.com/edwingeng/slog"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"google.golang.org/grpc/grpclog"
+	"strings"
+	"testing"
+)
+
+func TestLoggerV2(t *testing.T) {
+	sc := slog.NewScavenger()
+	lv := NewLoggerV2(sc)
+	lv.Info("a", 1)
+	lv.Infoln("b", 2)
+	lv.Infof("c%d", 3)
+	lv.Warning("d")
+	lv.Warningln("e", 5)
+	lv.Warningf("f%d", 6)
+	lv.Error("g")
+	lv.Errorln("h", 8)
+	lv.Errorf("i%d", 9)
+
+	dump := `INFO	a1
+INFO	b 2
+INFO	c3
+WARN	d
+WARN	e 5
+WARN	f6
+ERROR	g
+ERROR	h 8
+ERROR	i9
+`
+	if sc.Dump() != dump {
+		t.Fatal("something is wrong with LoggerV2: " + sc.Dump())
+	}
+}
+
+func TestLoggerV2_Fatal(t *testing.T) {
+	var code int
+	defer func(fn func(int)) { osExit = fn }(osExit)
+	osExit = func(c int) { code = c }
+
+	sc := slog.NewScavenger()
+	lv := NewLoggerV2(sc)
+	lv.Fatal("a")
+	lv.Fatalln("b", 2)
+	lv.Fatalf("c%d", 3)
+	if code != 1 || sc.Dump() != "ERROR\ta\nERROR\tb 2\nERROR\tc3\n" {
+		t.Fatal("something is wrong with Fatal: " + sc.Dump())
+	}
+}
+
+func TestLoggerV2_V(t *testing.T) {
+	sc := slog.NewScavenger(slog.WithMinLevel(slog.ZapInfoLevel))
+	lv := NewLoggerV2(sc, WithVerbosity(2))
+	if !lv.V(0) || !lv.V(2) || lv.V(3) {
+		t.Fatal("V does not work as expected")
+	}
+
+	lv = NewLoggerV2(sc, WithVerbosity(2), WithInfoLevel(slog.ZapDebugLevel))
+	if lv.V(0) {
+		t.Fatal("V does not work as expected")
+	}
+	lv.Info("skipped")
+	sc.SetMinLevel(slog.ZapDebugLevel)
+	lv.Info("hello")
+	if !lv.V(1) || sc.Dump() != "DEBUG\thello\n" {
+		t.Fatal("something is wrong with WithInfoLevel: " + sc.Dump())
+	}
+}
+
+func TestSetLoggerV2(t *testing.T) {
+	sc := slog.NewScavenger()
+	grpclog.SetLoggerV2(NewLoggerV2(sc))
+	grpclog.Warning("transport is closing")
+	if !sc.Exists("transport is closing") {
+		t.Fatal("something is wrong with SetLoggerV2: " + sc.Dump())
+	}
+}
+
+func TestLoggerV2_Caller(t *testing.T) {
+	var buf bytes.Buffer
+	enc := zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	core := zapcore.NewCore(enc, zapcore.AddSync(&buf), zapcore.DebugLevel)
+	grpclog.SetLoggerV2(NewLoggerV2(slog.NewZapLogger(zap.New(core, zap.AddCaller()).Sugar())))
+	grpclog.Info("hello")
+	grpclog.Warningf("hello %d", 2)
+	grpclog.Component("transport").Error("closing")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 || !strings.HasSuffix(lines[2], "[transport] closing") {
+		t.Fatal("something is wrong with LoggerV2: " + buf.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "sloggrpc/sloggrpc_test.go:") {
+			t.Fatal("the caller of grpclog should be reported: " + line)
+		}
+	}
+}
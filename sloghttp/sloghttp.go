@@ -0,0 +1,323 @@
+// Package sloghttp provides net/http middleware that writes access logs to a slog.Logger.
+package sloghttp
+
+import (
+	"bufio"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/edwingeng/slog"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultRequestIDHeader is the default header carrying the request id.
+const DefaultRequestIDHeader = "X-Request-Id"
+
+// maxRequestIDLength is the maximum length of a request id taken from a request.
+const maxRequestIDLength = 128
+
+type ctxKey struct{}
+
+// NewContext returns a copy of ctx that carries l.
+func NewContext(ctx context.Context, l slog.Logger) context.Context {
+	return context.WithValue(ctx, ctxKey{}, l)
+}
+
+// FromContext returns the Logger carried by ctx. It returns a devourer if there is none.
+func FromContext(ctx context.Context) slog.Logger {
+	if l, ok := ctx.Value(ctxKey{}).(slog.Logger); ok {
+		return l
+	}
+	return slog.NewDevourer()
+}
+
+type options struct {
+	requestIDHeader string
+	levelFunc       func(status int) int
+	skip            func(r *http.Request) bool
+	trustProxy      bool
+}
+
+// Option configures Middleware.
+type Option func(opts *options)
+
+// WithRequestIDHeader sets the header from which the request id is read. A request id is
+// generated if the header is absent, longer than 128 bytes, or contains characters other
+// than ASCII letters, digits, '-', '_' and '.'. The request id is also written to the
+// response header.
+func WithRequestIDHeader(name string) Option {
+	return func(opts *options) {
+		opts.requestIDHeader = name
+	}
+}
+
+// WithLevelFunc sets the function that picks the log level of the completion entry
+// according to the status code. See DefaultLevel for the default behavior.
+func WithLevelFunc(fn func(status int) int) Option {
+	return func(opts *options) {
+		opts.levelFunc = fn
+	}
+}
+
+// WithSkip sets a predicate telling which requests must not produce a completion entry.
+// The request still gets a Logger in its context.
+func WithSkip(fn func(r *http.Request) bool) Option {
+	return func(opts *options) {
+		opts.skip = fn
+	}
+}
+
+// WithSkipPaths is like WithSkip but skips the requests whose path is one of paths,
+// e.g. "/healthz".
+func WithSkipPaths(paths ...string) Option {
+	m := make(map[string]bool, len(paths))
+	for _, p := range paths {
+		m[p] = true
+	}
+	return WithSkip(func(r *http.Request) bool {
+		return m[r.URL.Path]
+	})
+}
+
+// WithTrustedProxyHeaders makes Middleware take the client IP from the X-Forwarded-For
+// and X-Real-Ip headers. Enable it only behind a proxy that sets these headers.
+func WithTrustedProxyHeaders() Option {
+	return func(opts *options) {
+		opts.trustProxy = true
+	}
+}
+
+// DefaultLevel logs 5xx responses at Error, 4xx responses at Warn and the others at Info.
+func DefaultLevel(status int) int {
+	switch {
+	case status >= 500:
+		return slog.ZapErrorLevel
+	case status >= 400:
+		return slog.ZapWarnLevel
+	default:
+		return slog.ZapInfoLevel
+	}
+}
+
+// Middleware returns net/http middleware that creates a child Logger of l for every
+// request through NewLoggerWith, with the method, path and request id as fields, stores
+// it in the request context, and logs a completion entry with the status code, the
+// number of bytes written, the duration and the client IP. If the handler panics, the
+// entry is logged with status 500 and the panic value before the panic continues.
+func Middleware(l slog.Logger, opts ...Option) func(http.Handler) http.Handler {
+	options := options{
+		requestIDHeader: DefaultRequestIDHeader,
+		levelFunc:       DefaultLevel,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			requestID := r.Header.Get(options.requestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = newRequestID()
+			}
+			w.Header().Set(options.requestIDHeader, requestID)
+
+			logger := l.NewLoggerWith("method", r.Method, "path", r.URL.Path, "request_id", requestID)
+			rw := &responseWriter{ResponseWriter: w}
+			defer func() {
+				v := recover()
+				if options.skip == nil || !options.skip(r) {
+					logCompletion(logger, &options, r, rw, start, v)
+				}
+				if v != nil {
+					panic(v)
+				}
+			}()
+			next.ServeHTTP(rw.wrap(), r.WithContext(NewContext(r.Context(), logger)))
+		})
+	}
+}
+
+// logCompletion logs the completion entry of r. If the handler panicked with
+// panicValue, the status is logged as 500 along with the panic value.
+func logCompletion(logger slog.Logger, options *options, r *http.Request, rw *responseWriter, start time.Time, panicValue any) {
+	status := rw.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	if panicValue != nil {
+		status = http.StatusInternalServerError
+	}
+	kvs := []any{
+		"status", status,
+		"bytes", rw.bytes,
+		"duration", time.Since(start),
+		"client_ip", clientIP(r, options.trustProxy),
+	}
+	if panicValue != nil {
+		kvs = append(kvs, "panic", fmt.Sprint(panicValue))
+	}
+	switch level := options.levelFunc(status); {
+	case level <= slog.ZapDebugLevel:
+		logger.Debugw("request completed", kvs...)
+	case level == slog.ZapInfoLevel:
+		logger.Infow("request completed", kvs...)
+	case level == slog.ZapWarnLevel:
+		logger.Warnw("request completed", kvs...)
+	default:
+		logger.Errorw("request completed", kvs...)
+	}
+}
+
+func newRequestID() string {
+	var b [16]byte
+	_, _ = rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		switch c := id[i]; {
+		case c >= 'a' && c <= 'z', c >= 'A' && c <= 'Z', c >= '0' && c <= '9':
+		case c == '-', c == '_', c == '.':
+		default:
+			return false
+		}
+	}
+	return true
+}
+
+func clientIP(r *http.Request, trustProxy bool) string {
+	if trustProxy {
+		if xff := r.Header.Get("X-Forwarded-For"); xff != "" {
+			first, _, _ := strings.Cut(xff, ",")
+			return strings.TrimSpace(first)
+		}
+		if ip := r.Header.Get("X-Real-Ip"); ip != "" {
+			return ip
+		}
+	}
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+	return host
+}
+
+type responseWriter struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func (rw *responseWriter) WriteHeader(status int) {
+	if rw.status == 0 {
+		rw.status = status
+	}
+	rw.ResponseWriter.WriteHeader(status)
+}
+
+func (rw *responseWriter) Write(p []byte) (int, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := rw.ResponseWriter.Write(p)
+	rw.bytes += int64(n)
+	return n, err
+}
+
+// wrap returns rw as a ResponseWriter that implements http.Flusher, http.Hijacker and
+// io.ReaderFrom only if the underlying ResponseWriter does, so that handlers detecting
+// these interfaces are not misled.
+func (rw *responseWriter) wrap() http.ResponseWriter {
+	_, fl := rw.ResponseWriter.(http.Flusher)
+	_, hj := rw.ResponseWriter.(http.Hijacker)
+	_, rf := rw.ResponseWriter.(io.ReaderFrom)
+	type base interface {
+		http.ResponseWriter
+		Unwrap() http.ResponseWriter
+	}
+	switch {
+	case fl && hj && rf:
+		return struct {
+			base
+			http.Flusher
+			http.Hijacker
+			io.ReaderFrom
+		}{rw, rw, rw, rw}
+	case fl && hj:
+		return struct {
+			base
+			http.Flusher
+			http.Hijacker
+		}{rw, rw, rw}
+	case fl && rf:
+		return struct {
+			base
+			http.Flusher
+			io.ReaderFrom
+		}{rw, rw, rw}
+	case hj && rf:
+		return struct {
+			base
+			http.Hijacker
+			io.ReaderFrom
+		}{rw, rw, rw}
+	case fl:
+		return struct {
+			base
+			http.Flusher
+		}{rw, rw}
+	case hj:
+		return struct {
+			base
+			http.Hijacker
+		}{rw, rw}
+	case rf:
+		return struct {
+			base
+			io.ReaderFrom
+		}{rw, rw}
+	default:
+		return struct {
+			base
+		}{rw}
+	}
+}
+
+func (rw *responseWriter) Flush() {
+	rw.ResponseWriter.(http.Flusher).Flush()
+}
+
+// ReadFrom lets the underlying ResponseWriter use sendfile and the like.
+func (rw *responseWriter) ReadFrom(src io.Reader) (int64, error) {
+	if rw.status == 0 {
+		rw.status = http.StatusOK
+	}
+	n, err := rw.ResponseWriter.(io.ReaderFrom).ReadFrom(src)
+	rw.bytes += n
+	return n, err
+}
+
+// Hijack lets the handler take over the connection, e.g. for WebSocket. The status
+// is logged as 101 unless the handler has written a header before.
+func (rw *responseWriter) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, brw, err := rw.ResponseWriter.(http.Hijacker).Hijack()
+	if err == nil && rw.status == 0 {
+		rw.status = http.StatusSwitchingProtocols
+	}
+	return conn, brw, err
+}
+
+// Unwrap allows http.ResponseController to reach the underlying ResponseWriter.
+func (rw *responseWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
@@ -0,0 +1,218 @@
+package sloghttp
+
+import (
+	"bufio"
+	"context"
+	"github.com/edwingeng/slog"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newHandler(sc *slog.Scavenger, opts ...Option) http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/users", func(w http.ResponseWriter, r *http.Request) {
+		FromContext(r.Context()).Info("listing users")
+		_, _ = w.Write([]byte("hello"))
+	})
+	mux.HandleFunc("/missing", func(w http.ResponseWriter, r *http.Request) {
+		http.NotFound(w, r)
+	})
+	mux.HandleFunc("/panic", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusInternalServerError)
+	})
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {})
+	return Middleware(sc, opts...)(mux)
+}
+
+func TestMiddleware(t *testing.T) {
+	sc := slog.NewScavenger()
+	h := newHandler(sc, WithSkipPaths("/healthz"))
+
+	req := httptest.NewRequest(http.MethodGet, "/users", nil)
+	req.Header.Set("X-Request-Id", "abc")
+	req.RemoteAddr = "10.0.0.1:1234"
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Header().Get("X-Request-Id") != "abc" {
+		t.Fatal("the request id should be written to the response")
+	}
+
+	rex := regexp.MustCompile(`^request completed	\{"method": "GET", "path": "/users", "request_id": "abc", "status": 200, "bytes": 5, "duration": "[^"]+", "client_ip": "10\.0\.0\.1"\}$`)
+	if sc.Len() != 2 || sc.LogEntry(0).Message != `listing users	{"method": "GET", "path": "/users", "request_id": "abc"}` {
+		t.Fatal("something is wrong with Middleware: " + sc.Dump())
+	}
+	if e := sc.LogEntry(1); e.Level != slog.LevelInfo || !rex.MatchString(e.Message) {
+		t.Fatal("something is wrong with Middleware: " + sc.Dump())
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	if sc.Len() != 2 {
+		t.Fatal("/healthz should be skipped")
+	}
+
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/missing", nil))
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPost, "/panic", nil))
+	if sc.Len() != 4 || sc.LogEntry(2).Level != slog.LevelWarn || sc.LogEntry(3).Level != slog.LevelError {
+		t.Fatal("something is wrong with Middleware: " + sc.Dump())
+	}
+	if !regexp.MustCompile(`"request_id": "[0-9a-f]{32}"`).MatchString(sc.LogEntry(2).Message) {
+		t.Fatal("a request id should be generated: " + sc.Dump())
+	}
+}
+
+func TestMiddleware_Panic(t *testing.T) {
+	sc := slog.NewScavenger()
+	h := Middleware(sc)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		panic("boom")
+	}))
+
+	var v any
+	func() {
+		defer func() { v = recover() }()
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/users", nil))
+	}()
+	if v != "boom" {
+		t.Fatal("the panic should continue after the completion entry is logged")
+	}
+	e := sc.LogEntry(0)
+	if sc.Len() != 1 || e.Level != slog.LevelError || !strings.Contains(e.Message, `"status": 500`) || !strings.Contains(e.Message, `"panic": "boom"`) {
+		t.Fatal("something is wrong with Middleware: " + sc.Dump())
+	}
+}
+
+func TestMiddleware_Options(t *testing.T) {
+	sc := slog.NewScavenger()
+	h := newHandler(sc,
+		WithRequestIDHeader("X-Trace"),
+		WithTrustedProxyHeaders(),
+		WithLevelFunc(func(status int) int { return slog.ZapDebugLevel }),
+		WithSkip(func(r *http.Request) bool { return r.Method == http.MethodHead }),
+	)
+
+	req := httptest.NewRequest(http.MethodPost, "/panic", nil)
+	req.Header.Set("X-Trace", "t1")
+	req.Header.Set("X-Forwarded-For", "1.2.3.4, 10.0.0.1")
+	h.ServeHTTP(httptest.NewRecorder(), req)
+	h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodHead, "/users", nil))
+
+	if sc.Len() != 2 {
+		t.Fatal("something is wrong with Middleware: " + sc.Dump())
+	}
+	e := sc.LogEntry(0)
+	if e.Level != slog.LevelDebug || !regexp.MustCompile(`"request_id": "t1", "status": 500, .+"client_ip": "1\.2\.3\.4"`).MatchString(e.Message) {
+		t.Fatal("something is wrong with Middleware: " + sc.Dump())
+	}
+}
+
+func TestMiddleware_RequestID(t *testing.T) {
+	sc := slog.NewScavenger()
+	h := newHandler(sc)
+	for _, id := range []string{"bad id", "a\"b", strings.Repeat("x", 129)} {
+		req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+		req.Header.Set("X-Request-Id", id)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		if !regexp.MustCompile(`^[0-9a-f]{32}$`).MatchString(rec.Header().Get("X-Request-Id")) {
+			t.Fatalf("an invalid request id should be replaced: %q", id)
+		}
+	}
+
+	req := httptest.NewRequest(http.MethodGet, "/healthz", nil)
+	req.Header.Set("X-Request-Id", "Req-1.2_3")
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+	if rec.Header().Get("X-Request-Id") != "Req-1.2_3" {
+		t.Fatal("a valid request id should be kept")
+	}
+}
+
+func TestMiddleware_ReadFromAndHijack(t *testing.T) {
+	sc := slog.NewScavenger()
+	mux := http.NewServeMux()
+	mux.HandleFunc("/copy", func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(io.ReaderFrom); !ok {
+			t.Error("the ResponseWriter should implement io.ReaderFrom")
+		}
+		_, _ = io.Copy(w, strings.NewReader("hello, world"))
+	})
+	mux.HandleFunc("/ws", func(w http.ResponseWriter, r *http.Request) {
+		conn, brw, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		defer conn.Close()
+		_, _ = brw.WriteString("HTTP/1.1 101 Switching Protocols\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+		_ = brw.Flush()
+	})
+	srv := httptest.NewServer(Middleware(sc)(mux))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/copy")
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if string(body) != "hello, world" {
+		t.Fatal("ReadFrom does not work as expected")
+	}
+
+	conn, err := net.Dial("tcp", srv.Listener.Addr().String())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_, _ = io.WriteString(conn, "GET /ws HTTP/1.1\r\nHost: x\r\nUpgrade: websocket\r\nConnection: Upgrade\r\n\r\n")
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil || line != "HTTP/1.1 101 Switching Protocols\r\n" {
+		t.Fatal("Hijack does not work as expected")
+	}
+
+	// The completion entry of a hijacked connection is logged after the handler returns.
+	for i := 0; i < 100 && sc.Len() < 2; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	if sc.Len() != 2 ||
+		!strings.Contains(sc.LogEntry(0).Message, `"status": 200, "bytes": 12`) ||
+		!strings.Contains(sc.LogEntry(1).Message, `"status": 101`) {
+		t.Fatal("something is wrong with Middleware: " + sc.Dump())
+	}
+}
+
+func TestMiddleware_Interfaces(t *testing.T) {
+	var fl, hj, rf, uw bool
+	h := Middleware(slog.NewScavenger())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, fl = w.(http.Flusher)
+		_, hj = w.(http.Hijacker)
+		_, rf = w.(io.ReaderFrom)
+		_, uw = w.(interface{ Unwrap() http.ResponseWriter })
+	}))
+
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+	if !fl || hj || rf || !uw {
+		t.Fatal("the ResponseWriter should implement http.Flusher only")
+	}
+
+	h.ServeHTTP(struct{ http.ResponseWriter }{httptest.NewRecorder()}, httptest.NewRequest(http.MethodGet, "/", nil))
+	if fl || hj || rf || !uw {
+		t.Fatal("the ResponseWriter should implement none of the optional interfaces")
+	}
+}
+
+func TestFromContext(t *testing.T) {
+	if FromContext(context.Background()).LogLevelEnabled(slog.ZapErrorLevel) {
+		t.Fatal("FromContext should return a devourer")
+	}
+	sc := slog.NewScavenger()
+	if FromContext(NewContext(context.Background(), sc)) != sc {
+		t.Fatal("FromContext does not work as expected")
+	}
+}
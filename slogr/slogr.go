@@ -0,0 +1,104 @@
+// Package slogr implements a logr.LogSink on top of slog.Logger, so that libraries
+// logging through go-logr, e.g. the Kubernetes client libraries and controller-runtime,
+// can write to a ZapLogger or a Scavenger.
+package slogr
+
+import (
+	"github.com/edwingeng/slog"
+	"github.com/go-logr/logr"
+)
+
+// NameKey is the key of the field holding the name built by WithName.
+const NameKey = "logger"
+
+// ErrorKey is the key of the field holding the error passed to Error.
+const ErrorKey = "error"
+
+var (
+	_ logr.LogSink          = &sink{}
+	_ logr.CallDepthLogSink = &sink{}
+)
+
+type sink struct {
+	l    slog.Logger
+	name string
+}
+
+// NewLogger creates a new logr.Logger that writes to l.
+func NewLogger(l slog.Logger) logr.Logger {
+	return logr.New(NewLogSink(l))
+}
+
+// NewLogSink creates a new logr.LogSink that writes to l. V-level 0 is mapped to
+// Info and all greater V-levels are mapped to Debug.
+func NewLogSink(l slog.Logger) logr.LogSink {
+	return &sink{l: l}
+}
+
+// Init makes a *slog.ZapLogger report the caller of logr.Logger rather than the sink.
+func (s *sink) Init(info logr.RuntimeInfo) {
+	s.l = slog.AddCallerSkip(s.l, info.CallDepth+1)
+}
+
+func (s *sink) WithCallDepth(depth int) logr.LogSink {
+	return &sink{
+		l:    slog.AddCallerSkip(s.l, depth),
+		name: s.name,
+	}
+}
+
+func slogLevel(level int) int {
+	if level > 0 {
+		return slog.ZapDebugLevel
+	}
+	return slog.ZapInfoLevel
+}
+
+func (s *sink) Enabled(level int) bool {
+	return s.l.LogLevelEnabled(slogLevel(level))
+}
+
+func (s *sink) withName(keysAndValues []any) []any {
+	if s.name == "" {
+		return keysAndValues
+	}
+	kvs := make([]any, 0, len(keysAndValues)+2)
+	kvs = append(kvs, NameKey, s.name)
+	return append(kvs, keysAndValues...)
+}
+
+func (s *sink) Info(level int, msg string, keysAndValues ...any) {
+	if slogLevel(level) == slog.ZapDebugLevel {
+		s.l.Debugw(msg, s.withName(keysAndValues)...)
+	} else {
+		s.l.Infow(msg, s.withName(keysAndValues)...)
+	}
+}
+
+func (s *sink) Error(err error, msg string, keysAndValues ...any) {
+	kvs := make([]any, 0, len(keysAndValues)+4)
+	if s.name != "" {
+		kvs = append(kvs, NameKey, s.name)
+	}
+	kvs = append(kvs, ErrorKey, err)
+	kvs = append(kvs, keysAndValues...)
+	s.l.Errorw(msg, kvs...)
+}
+
+func (s *sink) WithValues(keysAndValues ...any) logr.LogSink {
+	return &sink{
+		l:    s.l.NewLoggerWith(keysAndValues...),
+		name: s.name,
+	}
+}
+
+func (s *sink) WithName(name string) logr.LogSink {
+	newName := name
+	if s.name != "" {
+		newName = s.name + "." + name
+	}
+	return &sink{
+		l:    s.l,
+		name: newName,
+	}
+}
@@ -0,0 +1,67 @@
+package slogr
+
+import (
+	"bytes"
+	"errors"
+	"github.com/edwingeng/slog"
+	"github.com/go-logr/logr"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"strings"
+	"testing"
+)
+
+func TestLogSink(t *testing.T) {
+	sc := slog.NewScavenger()
+	logger := NewLogger(sc)
+	logger.Info("starting", "workers", 2)
+	logger.V(1).Info("details", "foo", "bar")
+
+	reconciler := logger.WithName("controller").WithName("pod").WithValues("namespace", "default")
+	reconciler.Info("reconciling")
+	reconciler.Error(errors.New("not found"), "reconcile failed", "pod", "nginx")
+
+	dump := `INFO	starting	{"workers": 2}
+DEBUG	details	{"foo": "bar"}
+INFO	reconciling	{"namespace": "default", "logger": "controller.pod"}
+ERROR	reconcile failed	{"namespace": "default", "logger": "controller.pod", "error": "not found", "pod": "nginx"}
+`
+	if sc.Dump() != dump {
+		t.Fatal("something is wrong with the LogSink: " + sc.Dump())
+	}
+}
+
+func TestLogSink_Enabled(t *testing.T) {
+	sc := slog.NewScavenger(slog.WithMinLevel(slog.ZapInfoLevel))
+	logger := NewLogger(sc)
+	if !logger.Enabled() || logger.V(1).Enabled() {
+		t.Fatal("Enabled does not work as expected")
+	}
+	logger.V(2).Info("skipped")
+	if sc.Len() != 0 {
+		t.Fatal(`sc.Len() != 0`)
+	}
+}
+
+func TestLogSink_Caller(t *testing.T) {
+	var buf bytes.Buffer
+	enc := zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	core := zapcore.NewCore(enc, zapcore.AddSync(&buf), zapcore.DebugLevel)
+	logger := NewLogger(slog.NewZapLogger(zap.New(core, zap.AddCaller()).Sugar()))
+	logger.Info("hello")
+	logger.WithValues("foo", "bar").Error(errors.New("boom"), "failed")
+	helper := func(l logr.Logger) {
+		l.WithCallDepth(1).Info("helper")
+	}
+	helper(logger)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 3 {
+		t.Fatal("something is wrong with the LogSink: " + buf.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "slogr/slogr_test.go:") {
+			t.Fatal("the caller of logr.Logger should be reported: " + line)
+		}
+	}
+}
@@ -0,0 +1,264 @@
+package slog
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	spoolExt              = ".spool"
+	spoolRecordHeaderSize = 4
+	// spoolFirstSeq leaves room below the first segment for prepend.
+	spoolFirstSeq = 1 << 32
+)
+
+var (
+	errSpoolFull = errors.New("the spool is full")
+)
+
+var (
+	// openSpools holds the directories of the open spools. Two spools sharing a
+	// directory would consume each other's records.
+	openSpools struct {
+		sync.Mutex
+		m map[string]bool
+	}
+)
+
+// spool is an on-disk FIFO of records. It is made of segment files named after
+// increasing sequence numbers, each holding length-prefixed records. spool is not
+// safe for concurrent use.
+type spool struct {
+	dir         string
+	absDir      string
+	maxSize     int64
+	segmentSize int64
+
+	size     int64
+	segments []uint64
+	w        *os.File
+	wSize    int64
+	r        *os.File
+	rOff     int64
+}
+
+// openSpool opens the spool in dir. The directory must not be used by another spool,
+// which openSpool can only check within the process.
+func openSpool(dir string, maxSize, segmentSize int64) (*spool, error) {
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, err
+	}
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	openSpools.Lock()
+	defer openSpools.Unlock()
+	if openSpools.m[absDir] {
+		return nil, fmt.Errorf("the spool directory is in use: %s", dir)
+	}
+
+	sp := &spool{dir: dir, absDir: absDir, maxSize: maxSize, segmentSize: segmentSize}
+	for _, e := range entries {
+		seq, err := strconv.ParseUint(strings.TrimSuffix(e.Name(), spoolExt), 10, 64)
+		if err != nil || !strings.HasSuffix(e.Name(), spoolExt) || !e.Type().IsRegular() {
+			continue
+		}
+		info, err := e.Info()
+		if err != nil {
+			return nil, err
+		}
+		sp.segments = append(sp.segments, seq)
+		sp.size += info.Size()
+	}
+	sort.Slice(sp.segments, func(i, j int) bool { return sp.segments[i] < sp.segments[j] })
+	if openSpools.m == nil {
+		openSpools.m = make(map[string]bool)
+	}
+	openSpools.m[absDir] = true
+	return sp, nil
+}
+
+func (sp *spool) path(seq uint64) string {
+	return filepath.Join(sp.dir, fmt.Sprintf("%020d%s", seq, spoolExt))
+}
+
+func (sp *spool) empty() bool {
+	return len(sp.segments) == 0
+}
+
+func (sp *spool) writeRecords(f *os.File, records [][]byte) (int64, error) {
+	var n int64
+	for _, rec := range records {
+		if sp.size+n+int64(spoolRecordHeaderSize+len(rec)) > sp.maxSize {
+			return n, errSpoolFull
+		}
+		buf := make([]byte, spoolRecordHeaderSize+len(rec))
+		binary.BigEndian.PutUint32(buf, uint32(len(rec)))
+		copy(buf[spoolRecordHeaderSize:], rec)
+		if _, err := f.Write(buf); err != nil {
+			return n, err
+		}
+		n += int64(len(buf))
+	}
+	return n, nil
+}
+
+// append adds records to the tail of the spool. It stops at the first record that
+// does not fit.
+func (sp *spool) append(records [][]byte) error {
+	for _, rec := range records {
+		if sp.w == nil || sp.wSize >= sp.segmentSize {
+			if err := sp.rotate(); err != nil {
+				return err
+			}
+		}
+		n, err := sp.writeRecords(sp.w, [][]byte{rec})
+		sp.size += n
+		sp.wSize += n
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (sp *spool) rotate() error {
+	if sp.w != nil {
+		_ = sp.w.Close()
+		sp.w = nil
+	}
+	seq := uint64(spoolFirstSeq)
+	if n := len(sp.segments); n > 0 {
+		seq = sp.segments[n-1] + 1
+	}
+	f, err := os.OpenFile(sp.path(seq), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return err
+	}
+	sp.w, sp.wSize = f, 0
+	sp.segments = append(sp.segments, seq)
+	return nil
+}
+
+// prepend adds records to the head of the spool, in a new segment.
+func (sp *spool) prepend(records [][]byte) error {
+	if len(records) == 0 {
+		return nil
+	}
+	if sp.empty() {
+		return sp.append(records)
+	}
+	seq := sp.segments[0] - 1
+	f, err := os.OpenFile(sp.path(seq), os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o644)
+	if err != nil {
+		return err
+	}
+	n, err := sp.writeRecords(f, records)
+	sp.size += n
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if n > 0 {
+		if sp.r != nil {
+			_ = sp.r.Close()
+			sp.r, sp.rOff = nil, 0
+		}
+		sp.segments = append([]uint64{seq}, sp.segments...)
+	} else {
+		_ = os.Remove(sp.path(seq))
+	}
+	return err
+}
+
+// peek returns up to n records from the head of the spool without removing them.
+// Unreadable segments are dropped.
+func (sp *spool) peek(n int) [][]byte {
+	for !sp.empty() {
+		if sp.r == nil {
+			f, err := os.Open(sp.path(sp.segments[0]))
+			if err != nil {
+				sp.dropHead()
+				continue
+			}
+			sp.r, sp.rOff = f, 0
+		}
+
+		var records [][]byte
+		off := sp.rOff
+		for len(records) < n {
+			var header [spoolRecordHeaderSize]byte
+			if _, err := sp.r.ReadAt(header[:], off); err != nil {
+				break
+			}
+			size := int64(binary.BigEndian.Uint32(header[:]))
+			if size > sp.maxSize {
+				break
+			}
+			rec := make([]byte, size)
+			if _, err := sp.r.ReadAt(rec, off+spoolRecordHeaderSize); err != nil {
+				break
+			}
+			records = append(records, rec)
+			off += int64(spoolRecordHeaderSize + len(rec))
+		}
+		if len(records) > 0 {
+			return records
+		}
+		// The head segment is exhausted. A truncated record, left by a crash, is
+		// discarded along with it.
+		sp.dropHead()
+	}
+	return nil
+}
+
+// commit removes the records returned by the last call to peek.
+func (sp *spool) commit(records [][]byte) {
+	for _, rec := range records {
+		sp.rOff += int64(spoolRecordHeaderSize + len(rec))
+	}
+}
+
+func (sp *spool) dropHead() {
+	seq := sp.segments[0]
+	if sp.r != nil {
+		_ = sp.r.Close()
+		sp.r, sp.rOff = nil, 0
+	}
+	if len(sp.segments) == 1 && sp.w != nil {
+		_ = sp.w.Close()
+		sp.w, sp.wSize = nil, 0
+	}
+	if info, err := os.Stat(sp.path(seq)); err == nil {
+		sp.size -= info.Size()
+	}
+	_ = os.Remove(sp.path(seq))
+	sp.segments = sp.segments[1:]
+}
+
+func (sp *spool) close() error {
+	var err error
+	for _, f := range []*os.File{sp.r, sp.w} {
+		if f != nil {
+			if e := f.Close(); e != nil {
+				err = e
+			}
+		}
+	}
+	sp.r, sp.w = nil, nil
+	openSpools.Lock()
+	delete(openSpools.m, sp.absDir)
+	openSpools.Unlock()
+	return err
+}
@@ -0,0 +1,105 @@
+package slog
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+
+	"go.uber.org/zap/zapcore"
+)
+
+// StacktraceConfig configures the stack traces captured by Config.Build.
+type StacktraceConfig struct {
+	// Level decides the levels at which stack traces are captured, e.g.
+	// zapcore.WarnLevel for Warn and above. Nil means zapcore.ErrorLevel.
+	Level zapcore.LevelEnabler
+	// MaxFrames limits the number of frames in a stack trace. Zero means no limit.
+	MaxFrames int
+	// KeepSlogFrames keeps the frames of slog and zap on top of the stack, which are
+	// skipped by default.
+	KeepSlogFrames bool
+	// Compact formats a stack trace as a single line, which suits JSON output.
+	Compact bool
+}
+
+const (
+	slogFuncPrefix = "github.com/edwingeng/slog."
+	zapFuncPrefix  = "go.uber.org/zap"
+)
+
+type stackCore struct {
+	zapcore.Core
+	cfg StacktraceConfig
+}
+
+func newStackCore(core zapcore.Core, cfg StacktraceConfig) *stackCore {
+	if cfg.Level == nil {
+		cfg.Level = zapcore.ErrorLevel
+	}
+	return &stackCore{Core: core, cfg: cfg}
+}
+
+func (c *stackCore) With(fields []zapcore.Field) zapcore.Core {
+	return &stackCore{
+		Core: c.Core.With(fields),
+		cfg:  c.cfg,
+	}
+}
+
+// Check lets the wrapped core, and therefore its sampler, decide whether the entry is
+// logged, and attaches a stack trace to the entries it accepts.
+func (c *stackCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	ce = c.Core.Check(ent, ce)
+	if ce != nil && c.cfg.Level.Enabled(ent.Level) && ce.Stack == "" {
+		ce.Stack = c.cfg.capture()
+	}
+	return ce
+}
+
+func isLoggingFrame(frame runtime.Frame) bool {
+	if strings.HasPrefix(frame.Function, zapFuncPrefix) {
+		return true
+	}
+	return strings.HasPrefix(frame.Function, slogFuncPrefix) && !strings.HasSuffix(frame.File, "_test.go")
+}
+
+func (cfg *StacktraceConfig) capture() string {
+	pcs := make([]uintptr, 64)
+	n := runtime.Callers(2, pcs)
+	frames := runtime.CallersFrames(pcs[:n])
+
+	var sb strings.Builder
+	var count int
+	top := true
+	for {
+		frame, more := frames.Next()
+		if top && !cfg.KeepSlogFrames && isLoggingFrame(frame) {
+			if !more {
+				break
+			}
+			continue
+		}
+		top = false
+		if cfg.MaxFrames > 0 && count >= cfg.MaxFrames {
+			break
+		}
+
+		if count > 0 {
+			if cfg.Compact {
+				sb.WriteString(" | ")
+			} else {
+				sb.WriteByte('\n')
+			}
+		}
+		if cfg.Compact {
+			_, _ = fmt.Fprintf(&sb, "%s (%s:%d)", frame.Function, frame.File, frame.Line)
+		} else {
+			_, _ = fmt.Fprintf(&sb, "%s\n\t%s:%d", frame.Function, frame.File, frame.Line)
+		}
+		count++
+		if !more {
+			break
+		}
+	}
+	return sb.String()
+}
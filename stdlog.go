@@ -0,0 +1,93 @@
+package slog
+
+import (
+	"bytes"
+	"log"
+	"strings"
+)
+
+// LevelParser extracts the log level from a line written through the standard log package.
+// ok is false if line does not specify a log level.
+type LevelParser func(line string) (level int, msg string, ok bool)
+
+var levelPrefixes = []struct {
+	prefix string
+	level  int
+}{
+	{"[DEBUG]", ZapDebugLevel},
+	{"[INFO]", ZapInfoLevel},
+	{"[WARN]", ZapWarnLevel},
+	{"[WARNING]", ZapWarnLevel},
+	{"[ERROR]", ZapErrorLevel},
+}
+
+// ParseLevelPrefix is a LevelParser that recognizes lines like "[WARN] disk is almost full".
+// The prefix is case-insensitive and is removed from the message.
+func ParseLevelPrefix(line string) (level int, msg string, ok bool) {
+	for _, x := range levelPrefixes {
+		if len(line) >= len(x.prefix) && strings.EqualFold(line[:len(x.prefix)], x.prefix) {
+			return x.level, strings.TrimLeft(line[len(x.prefix):], " "), true
+		}
+	}
+	return 0, line, false
+}
+
+func logAt(l Logger, level int, msg string) {
+	switch {
+	case level <= ZapDebugLevel:
+		l.Debug(msg)
+	case level == ZapInfoLevel:
+		l.Info(msg)
+	case level == ZapWarnLevel:
+		l.Warn(msg)
+	default:
+		l.Error(msg)
+	}
+}
+
+// stdLogCallerSkip makes a *ZapLogger report the caller of the standard logger rather
+// than logAt by skipping logAt, stdLogWriter.Write, log.(*Logger).output and, e.g.,
+// log.Printf.
+const stdLogCallerSkip = 4
+
+type stdLogWriter struct {
+	l      Logger
+	level  int
+	parser LevelParser
+}
+
+func (w *stdLogWriter) Write(p []byte) (int, error) {
+	msg := string(bytes.TrimSuffix(p, []byte("\n")))
+	level := w.level
+	if w.parser != nil {
+		if lv, m, ok := w.parser(msg); ok {
+			level, msg = lv, m
+		}
+	}
+	logAt(w.l, level, msg)
+	return len(p), nil
+}
+
+// RedirectStdLog redirects the output of the standard log package to l at level, e.g.
+// ZapInfoLevel. If parser is not nil, it can override the level line by line. The
+// flags and prefix of the standard logger are cleared until restore is called.
+func RedirectStdLog(l Logger, level int, parser LevelParser) (restore func()) {
+	return RedirectStdLogger(log.Default(), l, level, parser)
+}
+
+// RedirectStdLogger is like RedirectStdLog but redirects the output of std.
+func RedirectStdLogger(std *log.Logger, l Logger, level int, parser LevelParser) (restore func()) {
+	flags, prefix, out := std.Flags(), std.Prefix(), std.Writer()
+	std.SetFlags(0)
+	std.SetPrefix("")
+	std.SetOutput(&stdLogWriter{
+		l:      AddCallerSkip(l, stdLogCallerSkip),
+		level:  level,
+		parser: parser,
+	})
+	return func() {
+		std.SetFlags(flags)
+		std.SetPrefix(prefix)
+		std.SetOutput(out)
+	}
+}
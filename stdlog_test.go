@@ -0,0 +1,72 @@
+package slog
+
+import (
+	"bytes"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"io"
+	"log"
+	"strings"
+	"testing"
+)
+
+func TestRedirectStdLog(t *testing.T) {
+	sc := NewScavenger()
+	restore := RedirectStdLog(sc, ZapInfoLevel, ParseLevelPrefix)
+	log.Printf("hello %d", 1)
+	log.Print("[warn] disk is almost full")
+	log.Println("[ERROR]boom")
+	log.Print("[DEBUG] multi\nline")
+	restore()
+
+	dump := `INFO	hello 1
+WARN	disk is almost full
+ERROR	boom
+DEBUG	multi
+line
+`
+	if sc.Dump() != dump {
+		t.Fatal("something is wrong with RedirectStdLog: " + sc.Dump())
+	}
+	if log.Flags() != log.LstdFlags || log.Prefix() != "" {
+		t.Fatal("restore does not work as expected")
+	}
+}
+
+func TestRedirectStdLogger(t *testing.T) {
+	var buf bytes.Buffer
+	std := log.New(&buf, "[lib] ", log.Lshortfile)
+	sc := NewScavenger()
+	restore := RedirectStdLogger(std, sc.NewLoggerWith("lib", "x"), ZapWarnLevel, nil)
+	std.Print("[INFO] hello")
+	restore()
+	std.Print("world")
+
+	if sc.Dump() != "WARN\t[INFO] hello\t{\"lib\": \"x\"}\n" {
+		t.Fatal("something is wrong with RedirectStdLogger: " + sc.Dump())
+	}
+	if !strings.HasPrefix(buf.String(), "[lib] stdlog_test.go:") || !strings.HasSuffix(buf.String(), ": world\n") {
+		t.Fatal("restore does not work as expected: " + buf.String())
+	}
+}
+
+func TestRedirectStdLogger_Caller(t *testing.T) {
+	var buf bytes.Buffer
+	enc := zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	core := zapcore.NewCore(enc, zapcore.AddSync(&buf), zapcore.DebugLevel)
+	std := log.New(io.Discard, "", 0)
+	restore := RedirectStdLogger(std, NewZapLogger(zap.New(core, zap.AddCaller()).Sugar()), ZapInfoLevel, nil)
+	defer restore()
+	std.Printf("hello %d", 1)
+	std.Println("world")
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 2 {
+		t.Fatal("something is wrong with RedirectStdLogger: " + buf.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "/stdlog_test.go:") {
+			t.Fatal("the caller of the standard logger should be reported: " + line)
+		}
+	}
+}
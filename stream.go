@@ -0,0 +1,135 @@
+package slog
+
+import (
+	"context"
+	"sync"
+)
+
+const defaultStreamBufferSize = 256
+
+type streamOptions struct {
+	replay     bool
+	bufferSize int
+	onOverflow func(e LogEntry)
+}
+
+// StreamOption configures Scavenger.Stream.
+type StreamOption func(opts *streamOptions)
+
+// StreamReplay makes Stream deliver the log messages collected before the call first.
+func StreamReplay() StreamOption {
+	return func(opts *streamOptions) {
+		opts.replay = true
+	}
+}
+
+// StreamBuffer sets the maximum number of live log messages Stream buffers for a slow
+// consumer. The default size is 256.
+func StreamBuffer(size int) StreamOption {
+	return func(opts *streamOptions) {
+		opts.bufferSize = size
+	}
+}
+
+// StreamOnOverflow sets a function to be called with every log message that Stream drops
+// because its buffer is full.
+func StreamOnOverflow(fn func(e LogEntry)) StreamOption {
+	return func(opts *streamOptions) {
+		opts.onOverflow = fn
+	}
+}
+
+type streamer struct {
+	mu      sync.Mutex
+	queue   []LogEntry
+	replays int
+	limit   int
+	wake    chan struct{}
+
+	onOverflow func(e LogEntry)
+}
+
+func (s *streamer) push(e LogEntry) {
+	s.mu.Lock()
+	if len(s.queue)-s.replays >= s.limit {
+		s.mu.Unlock()
+		if s.onOverflow != nil {
+			s.onOverflow(e)
+		}
+		return
+	}
+	s.queue = append(s.queue, e)
+	s.mu.Unlock()
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *streamer) pop() (LogEntry, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.queue) == 0 {
+		return LogEntry{}, false
+	}
+	e := s.queue[0]
+	s.queue = s.queue[1:]
+	if s.replays > 0 {
+		s.replays--
+	}
+	return e, true
+}
+
+// Stream returns a channel that delivers the log messages collected by sc from now on.
+// Up to StreamBuffer log messages are buffered if the consumer falls behind, and the
+// rest are dropped and reported to the StreamOnOverflow function. The channel is closed
+// after ctx is done.
+//
+// As with Subscribe, log messages collected concurrently may be delivered in a different
+// order than they are collected, and the StreamOnOverflow function may still be called
+// by another goroutine after the channel is closed.
+func (sc *Scavenger) Stream(ctx context.Context, opts ...StreamOption) <-chan LogEntry {
+	options := streamOptions{
+		bufferSize: defaultStreamBufferSize,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	s := &streamer{
+		limit:      options.bufferSize,
+		wake:       make(chan struct{}, 1),
+		onOverflow: options.onOverflow,
+	}
+	unsubscribe := sc.subscribe(&subscriber{fn: s.push}, func() {
+		if options.replay {
+			entries := sc.view()
+			s.queue = make([]LogEntry, len(entries))
+			copy(s.queue, entries)
+			s.replays = len(entries)
+		}
+	})
+
+	ch := make(chan LogEntry)
+	go func() {
+		defer close(ch)
+		defer unsubscribe()
+		for {
+			if e, ok := s.pop(); ok {
+				select {
+				case ch <- e:
+				case <-ctx.Done():
+					return
+				}
+				continue
+			}
+			select {
+			case <-s.wake:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,87 @@
+package slog
+
+import (
+	"context"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func receive(t *testing.T, ch <-chan LogEntry) LogEntry {
+	t.Helper()
+	select {
+	case e := <-ch:
+		return e
+	case <-time.After(time.Second * 5):
+		t.Fatal("timeout")
+		return LogEntry{}
+	}
+}
+
+func TestScavenger_Stream(t *testing.T) {
+	var sc = NewScavenger()
+	sc.Info("1")
+	sc.Info("2")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch1 := sc.Stream(ctx)
+	ch2 := sc.Stream(ctx, StreamReplay())
+	sc.Info("3")
+
+	if e := receive(t, ch1); e.Message != "3" {
+		t.Fatal("Stream does not work as expected")
+	}
+	for i := 1; i <= 3; i++ {
+		if e := receive(t, ch2); e.Message != strconv.Itoa(i) {
+			t.Fatal("Stream does not work as expected")
+		}
+	}
+
+	cancel()
+	for range ch1 {
+	}
+	for range ch2 {
+	}
+	sc.Info("4")
+	sc.mu.Lock()
+	n := len(sc.subs)
+	sc.mu.Unlock()
+	if n != 0 {
+		t.Fatal("Stream should unsubscribe after ctx is done")
+	}
+}
+
+func TestScavenger_Stream_Overflow(t *testing.T) {
+	var sc = NewScavenger()
+	for i := 0; i < 5; i++ {
+		sc.Info(i)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	var dropped []string
+	ch := sc.Stream(ctx, StreamReplay(), StreamBuffer(2), StreamOnOverflow(func(e LogEntry) {
+		dropped = append(dropped, e.Message)
+	}))
+	for i := 5; i < 10; i++ {
+		sc.Info(i)
+	}
+
+	var received []string
+	for i := 0; i < 7; i++ {
+		received = append(received, receive(t, ch).Message)
+	}
+	for i, str := range []string{"0", "1", "2", "3", "4"} {
+		if received[i] != str {
+			t.Fatal("Stream should replay all the collected log messages")
+		}
+	}
+	if len(dropped)+len(received) != 10 || dropped[len(dropped)-1] != "9" {
+		t.Fatal("Stream does not report the dropped log messages as expected")
+	}
+	select {
+	case e := <-ch:
+		t.Fatal("unexpected log message: " + e.Message)
+	case <-time.After(time.Millisecond * 10):
+	}
+}
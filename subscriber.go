@@ -0,0 +1,112 @@
+package slog
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+type subscriber struct {
+	filter func(e *LogEntry) bool
+	fn     func(e LogEntry)
+	ch     chan<- LogEntry
+
+	cancelled int32
+	done      chan struct{}
+	once      sync.Once
+}
+
+func (sub *subscriber) deliver(e *LogEntry) {
+	if atomic.LoadInt32(&sub.cancelled) != 0 {
+		return
+	}
+	if sub.filter != nil && !sub.filter(e) {
+		return
+	}
+	if sub.fn != nil {
+		sub.fn(*e)
+		return
+	}
+	select {
+	case sub.ch <- *e:
+	case <-sub.done:
+	}
+}
+
+func (sub *subscriber) cancel() {
+	sub.once.Do(func() {
+		atomic.StoreInt32(&sub.cancelled, 1)
+		close(sub.done)
+	})
+}
+
+// subscribe registers sub and calls init, if any, while no new entry can be collected.
+func (sc *Scavenger) subscribe(sub *subscriber, init func()) (unsubscribe func()) {
+	if prev := sub.filter; prev == nil {
+		sub.filter = sc.filter
+	} else if view := sc.filter; view != nil {
+		sub.filter = func(e *LogEntry) bool {
+			return view(e) && prev(e)
+		}
+	}
+	sub.done = make(chan struct{})
+
+	sc.mu.Lock()
+	subs := make([]*subscriber, 0, len(sc.subs)+1)
+	subs = append(subs, sc.subs...)
+	sc.subs = append(subs, sub)
+	if init != nil {
+		init()
+	}
+	sc.mu.Unlock()
+
+	return func() {
+		sub.cancel()
+		sc.mu.Lock()
+		defer sc.mu.Unlock()
+		for i, x := range sc.subs {
+			if x == sub {
+				subs := make([]*subscriber, 0, len(sc.subs)-1)
+				subs = append(subs, sc.subs[:i]...)
+				sc.subs = append(subs, sc.subs[i+1:]...)
+				break
+			}
+		}
+	}
+}
+
+func levelMessageFilter(fn func(level, msg string) bool) func(e *LogEntry) bool {
+	if fn == nil {
+		return nil
+	}
+	return func(e *LogEntry) bool {
+		return fn(e.Level, e.Message)
+	}
+}
+
+// Subscribe registers callback to be invoked for each log message collected afterwards
+// that satisfies the predicate fn. A nil fn accepts all the log messages. The callback is
+// invoked synchronously by the goroutine that logs the message, outside any internal lock,
+// so it must be safe for concurrent use when logging happens on multiple goroutines.
+// Subscribe returns a function that cancels the subscription.
+//
+// As a consequence, log messages collected concurrently may reach the callback in a
+// different order than they are collected, i.e. than Dump shows them, and the callback
+// may still be running on another goroutine when unsubscribe returns.
+func (sc *Scavenger) Subscribe(fn func(level, msg string) bool, callback func(e LogEntry)) (unsubscribe func()) {
+	return sc.subscribe(&subscriber{
+		filter: levelMessageFilter(fn),
+		fn:     callback,
+	}, nil)
+}
+
+// SubscribeChan is like Subscribe but sends the log messages to ch. Sending blocks the
+// logging goroutine until ch accepts the log message or the subscription is cancelled.
+// Likewise, log messages collected concurrently may be sent in a different order than
+// they are collected, and a log message may still be sent by another goroutine after
+// unsubscribe returns.
+func (sc *Scavenger) SubscribeChan(fn func(level, msg string) bool, ch chan<- LogEntry) (unsubscribe func()) {
+	return sc.subscribe(&subscriber{
+		filter: levelMessageFilter(fn),
+		ch:     ch,
+	}, nil)
+}
@@ -0,0 +1,120 @@
+package slog
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScavenger_Subscribe(t *testing.T) {
+	var sc = NewScavenger()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var a []LogEntry
+	unsubscribe := sc.Subscribe(func(level, msg string) bool {
+		return strings.HasPrefix(msg, "shutting down")
+	}, func(e LogEntry) {
+		a = append(a, e)
+		cancel()
+	})
+
+	child := sc.NewLoggerWith("foo", 1)
+	sc.Info("hello")
+	child.Info("shutting down")
+	if len(a) != 1 || a[0].Scope[1] != 1 || ctx.Err() == nil {
+		t.Fatal("Subscribe does not work as expected")
+	}
+
+	unsubscribe()
+	unsubscribe()
+	sc.Info("shutting down")
+	if len(a) != 1 {
+		t.Fatal("unsubscribe does not work as expected")
+	}
+}
+
+func TestScavenger_Subscribe_Reentrant(t *testing.T) {
+	var sc = NewScavenger()
+	sc.Subscribe(func(level, msg string) bool {
+		return level == LevelError
+	}, func(e LogEntry) {
+		sc.Info("saw an error")
+	})
+	sc.Error("boom")
+	if sc.Dump() != "ERROR\tboom\nINFO\tsaw an error\n" {
+		t.Fatal("Subscribe does not work as expected")
+	}
+}
+
+func TestScavenger_Subscribe_ScopeView(t *testing.T) {
+	var sc = NewScavenger()
+	child := sc.NewLoggerWith("foo", 1).(*Scavenger)
+	var n int
+	child.ScopeView().Subscribe(nil, func(e LogEntry) {
+		n++
+	})
+	sc.Info("1")
+	child.Info("2")
+	if n != 1 {
+		t.Fatal("Subscribe should respect the view")
+	}
+}
+
+func TestScavenger_SubscribeChan(t *testing.T) {
+	var sc = NewScavenger()
+	ch := make(chan LogEntry)
+	unsubscribe := sc.SubscribeChan(func(level, msg string) bool {
+		return level == LevelError
+	}, ch)
+
+	go func() {
+		sc.Info("hello")
+		sc.Error("first error")
+	}()
+	select {
+	case e := <-ch:
+		if e.Message != "first error" {
+			t.Fatal("SubscribeChan does not work as expected")
+		}
+	case <-time.After(time.Second * 5):
+		t.Fatal("SubscribeChan does not work as expected")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		sc.Error("second error")
+		close(done)
+	}()
+	time.Sleep(time.Millisecond * 10)
+	unsubscribe()
+	<-done
+}
+
+func TestScavenger_Subscribe_Race(t *testing.T) {
+	var sc = NewScavenger()
+	var n int64
+	unsubscribe := sc.Subscribe(nil, func(e LogEntry) {
+		atomic.AddInt64(&n, 1)
+	})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 50; i++ {
+		wg.Add(1)
+		scav := sc.NewLoggerWith("i", i)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				scav.Infow("hello", "j", j)
+			}
+		}()
+	}
+	wg.Wait()
+	unsubscribe()
+	if atomic.LoadInt64(&n) != 5000 {
+		t.Fatal(`atomic.LoadInt64(&n) != 5000`)
+	}
+}
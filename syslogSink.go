@@ -0,0 +1,318 @@
+package slog
+
+import (
+	"bytes"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"net"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	syslogScheme = "syslog"
+
+	defaultSyslogDialTimeout  = 5 * time.Second
+	defaultSyslogWriteTimeout = 5 * time.Second
+)
+
+// SyslogFormat is the message format of a syslog sink.
+type SyslogFormat string
+
+const (
+	SyslogRFC5424 SyslogFormat = "rfc5424"
+	SyslogRFC3164 SyslogFormat = "rfc3164"
+)
+
+var (
+	_ zapcore.Core = &syslogCore{}
+	_ zap.Sink     = &syslogSink{}
+)
+
+var (
+	syslogFacilities = map[string]int{
+		"kern": 0, "user": 1, "mail": 2, "daemon": 3, "auth": 4, "syslog": 5, "lpr": 6, "news": 7,
+		"uucp": 8, "cron": 9, "authpriv": 10, "ftp": 11,
+		"local0": 16, "local1": 17, "local2": 18, "local3": 19,
+		"local4": 20, "local5": 21, "local6": 22, "local7": 23,
+	}
+)
+
+// newSyslogWriter creates a writer from a URL like syslog://host:514?transport=tcp or
+// syslog:///dev/log. An empty host means a unix socket at the path of the URL. The
+// query parameters are:
+//
+//	transport  udp (the default) or tcp, ignored for unix sockets
+//	format     rfc5424 (the default) or rfc3164
+//	facility   a facility name such as local0, or a number. The default is user
+//	app        the app name, which defaults to the name of the executable
+//	severity   the severity of the messages whose level is unknown, i.e. those written
+//	           through the syslog sink, which serves zap.Config.Build and
+//	           ErrorOutputPaths. The default is 6 (informational)
+func newSyslogWriter(u *url.URL) (*syslogWriter, error) {
+	q := u.Query()
+	s := &syslogWriter{
+		format:       SyslogRFC5424,
+		facility:     syslogFacilities["user"],
+		app:          filepath.Base(os.Args[0]),
+		pid:          os.Getpid(),
+		severity:     syslogSeverity(zapcore.InfoLevel),
+		dialTimeout:  defaultSyslogDialTimeout,
+		writeTimeout: defaultSyslogWriteTimeout,
+	}
+	if s.host, _ = os.Hostname(); s.host == "" {
+		s.host = "-"
+	}
+
+	switch {
+	case u.Host == "":
+		if u.Path == "" {
+			return nil, fmt.Errorf("the socket path of the syslog sink is missing: %s", u)
+		}
+		s.network, s.addr = "unix", u.Path
+	case q.Get("transport") == "" || q.Get("transport") == "udp":
+		s.network, s.addr = "udp", u.Host
+	case q.Get("transport") == "tcp":
+		s.network, s.addr = "tcp", u.Host
+	default:
+		return nil, fmt.Errorf("unknown transport of the syslog sink: %q", q.Get("transport"))
+	}
+	if v := q.Get("format"); v != "" {
+		switch SyslogFormat(v) {
+		case SyslogRFC5424, SyslogRFC3164:
+			s.format = SyslogFormat(v)
+		default:
+			return nil, fmt.Errorf("unknown format of the syslog sink: %q", v)
+		}
+	}
+	if v := q.Get("facility"); v != "" {
+		facility, ok := syslogFacilities[strings.ToLower(v)]
+		if !ok {
+			n, err := strconv.Atoi(v)
+			if err != nil || n < 0 || n > 23 {
+				return nil, fmt.Errorf("unknown facility of the syslog sink: %q", v)
+			}
+			facility = n
+		}
+		s.facility = facility
+	}
+	if v := q.Get("app"); v != "" {
+		s.app = v
+	}
+	if v := q.Get("severity"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 || n > 7 {
+			return nil, fmt.Errorf("invalid severity of the syslog sink: %q", v)
+		}
+		s.severity = n
+	}
+	return s, nil
+}
+
+type syslogWriter struct {
+	network      string
+	addr         string
+	format       SyslogFormat
+	facility     int
+	app          string
+	host         string
+	pid          int
+	severity     int
+	dialTimeout  time.Duration
+	writeTimeout time.Duration
+
+	mu   sync.Mutex
+	conn net.Conn
+	// stream reports whether conn is a stream connection, whose messages need framing.
+	stream bool
+}
+
+// write sends a message made of body and a header carrying severity and t. If the
+// connection turns out to be broken, write reconnects and tries once more.
+func (s *syslogWriter) write(severity int, t time.Time, body []byte) error {
+	header := s.format.header(s.facility*8+severity, t, s.host, s.app, s.pid)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var err error
+	for attempt := 0; attempt < 2; attempt++ {
+		if s.conn == nil {
+			if err = s.dial(); err != nil {
+				return err
+			}
+		}
+		_ = s.conn.SetWriteDeadline(time.Now().Add(s.writeTimeout))
+		if _, err = s.conn.Write(s.frame(header, body)); err == nil {
+			return nil
+		}
+		_ = s.conn.Close()
+		s.conn = nil
+	}
+	return err
+}
+
+// dial connects to the daemon. A unix socket is tried as a datagram socket first, and
+// then as a stream socket.
+func (s *syslogWriter) dial() error {
+	networks := []string{s.network}
+	if s.network == "unix" {
+		networks = []string{"unixgram", "unix"}
+	}
+	var err error
+	for _, network := range networks {
+		var conn net.Conn
+		if conn, err = net.DialTimeout(network, s.addr, s.dialTimeout); err == nil {
+			s.conn, s.stream = conn, network == "tcp" || network == "unix"
+			return nil
+		}
+	}
+	return err
+}
+
+// frame builds a message. Over a stream connection, messages are terminated by a
+// newline, except that RFC 5424 messages over TCP are framed by octet counting, as
+// described in RFC 6587.
+func (s *syslogWriter) frame(header string, body []byte) []byte {
+	var buf bytes.Buffer
+	octetCounting := s.stream && s.network == "tcp" && s.format == SyslogRFC5424
+	if octetCounting {
+		buf.WriteString(strconv.Itoa(len(header) + len(body)))
+		buf.WriteByte(' ')
+	}
+	buf.WriteString(header)
+	buf.Write(body)
+	if s.stream && !octetCounting {
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+func (s *syslogWriter) close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.conn == nil {
+		return nil
+	}
+	err := s.conn.Close()
+	s.conn = nil
+	return err
+}
+
+func (f SyslogFormat) header(pri int, t time.Time, host, app string, pid int) string {
+	if f == SyslogRFC3164 {
+		return fmt.Sprintf("<%d>%s %s %s[%d]: ", pri, t.Format(time.Stamp), host, app, pid)
+	}
+	return fmt.Sprintf("<%d>1 %s %s %s %d - - ", pri, t.Format("2006-01-02T15:04:05.000000Z07:00"), host, app, pid)
+}
+
+// syslogSink is the zap.Sink registered for the syslog scheme, which serves
+// zap.Config.Build and ErrorOutputPaths. A sink cannot see the level of an entry, so
+// every message is written at the severity given by the URL. Config.Build writes to
+// the syslog outputs among OutputPaths through a syslogCore instead.
+type syslogSink struct {
+	w *syslogWriter
+}
+
+func newSyslogSink(u *url.URL) (zap.Sink, error) {
+	w, err := newSyslogWriter(u)
+	if err != nil {
+		return nil, err
+	}
+	return &syslogSink{w: w}, nil
+}
+
+func (s *syslogSink) Write(p []byte) (n int, err error) {
+	if err := s.w.write(s.w.severity, time.Now(), bytes.TrimRight(p, "\r\n")); err != nil {
+		return 0, err
+	}
+	return len(p), nil
+}
+
+func (s *syslogSink) Sync() error {
+	return nil
+}
+
+func (s *syslogSink) Close() error {
+	return s.w.close()
+}
+
+// syslogCore writes entries to a syslog daemon, with severities mapped from their
+// levels.
+type syslogCore struct {
+	zapcore.LevelEnabler
+	enc zapcore.Encoder
+	w   *syslogWriter
+}
+
+// buildSyslogCore creates a core that writes to the syslog outputs among
+// cfg.OutputPaths, and returns it along with the other output paths. The core is nil
+// if there is no syslog output. Like zap does for the other outputs, the core is
+// wrapped by a sampler if cfg.Sampling is set.
+func buildSyslogCore(cfg *zap.Config) ([]string, zapcore.Core, error) {
+	var paths []string
+	var cores []zapcore.Core
+	for _, path := range cfg.OutputPaths {
+		u, err := url.Parse(path)
+		if err != nil || u.Scheme != syslogScheme {
+			paths = append(paths, path)
+			continue
+		}
+		w, err := newSyslogWriter(u)
+		if err != nil {
+			return nil, nil, err
+		}
+		enc, err := newEncoder(cfg.Encoding, cfg.EncoderConfig)
+		if err != nil {
+			return nil, nil, err
+		}
+		cores = append(cores, &syslogCore{LevelEnabler: cfg.Level, enc: enc, w: w})
+	}
+	if len(cores) == 0 {
+		return paths, nil, nil
+	}
+
+	core := zapcore.NewTee(cores...)
+	if sampling := cfg.Sampling; sampling != nil {
+		var opts []zapcore.SamplerOption
+		if sampling.Hook != nil {
+			opts = append(opts, zapcore.SamplerHook(sampling.Hook))
+		}
+		core = zapcore.NewSamplerWithOptions(core, time.Second, sampling.Initial, sampling.Thereafter, opts...)
+	}
+	return paths, core, nil
+}
+
+func (c *syslogCore) With(fields []zapcore.Field) zapcore.Core {
+	enc := c.enc.Clone()
+	for _, f := range fields {
+		f.AddTo(enc)
+	}
+	return &syslogCore{LevelEnabler: c.LevelEnabler, enc: enc, w: c.w}
+}
+
+func (c *syslogCore) Check(ent zapcore.Entry, ce *zapcore.CheckedEntry) *zapcore.CheckedEntry {
+	if c.Enabled(ent.Level) {
+		return ce.AddCore(ent, c)
+	}
+	return ce
+}
+
+func (c *syslogCore) Write(ent zapcore.Entry, fields []zapcore.Field) error {
+	buf, err := c.enc.EncodeEntry(ent, fields)
+	if err != nil {
+		return err
+	}
+	err = c.w.write(syslogSeverity(ent.Level), ent.Time, bytes.TrimRight(buf.Bytes(), "\r\n"))
+	buf.Free()
+	return err
+}
+
+func (c *syslogCore) Sync() error {
+	return nil
+}
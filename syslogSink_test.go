@@ -0,0 +1,220 @@
+package slog
+
+import (
+	"bufio"
+	"fmt"
+	"go.uber.org/zap"
+	"io"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+)
+
+func newSyslogLogger(t *testing.T, paths ...string) *ZapLogger {
+	t.Helper()
+	cfg := NewDevelopmentConfig()
+	cfg.Encoding = EncodingLogfmt
+	cfg.EncoderConfig.TimeKey = ""
+	cfg.EncoderConfig.CallerKey = ""
+	cfg.OutputPaths = paths
+	l, err := cfg.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return l
+}
+
+func readDatagrams(t *testing.T, pc net.PacketConn, n int) []string {
+	t.Helper()
+	_ = pc.SetReadDeadline(time.Now().Add(5 * time.Second))
+	var ret []string
+	buf := make([]byte, 65536)
+	for len(ret) < n {
+		k, _, err := pc.ReadFrom(buf)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ret = append(ret, string(buf[:k]))
+	}
+	return ret
+}
+
+func TestSyslogSink_UDP(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	sink := NewMemorySink("syslog-udp")
+	l := newSyslogLogger(t, "syslog://"+pc.LocalAddr().String()+"?facility=local0&app=myapp", "memory://syslog-udp")
+	l.Debug("d")
+	l.Info("i")
+	l.Warn("w")
+	l.Error("e")
+	if len(sink.Lines()) != 4 {
+		t.Fatal("the other outputs should receive the entries too: " + sink.String())
+	}
+
+	host, _ := os.Hostname()
+	re := regexp.MustCompile(`^<(\d+)>1 \d{4}-\d\d-\d\dT\d\d:\d\d:\d\d\.\d{6}\S+ (\S+) myapp (\d+) - - L=(\w+) M=(\w)$`)
+	for i, msg := range readDatagrams(t, pc, 4) {
+		m := re.FindStringSubmatch(msg)
+		if m == nil {
+			t.Fatal("the RFC 5424 message is not formatted as expected: " + msg)
+		}
+		expectedPri := fmt.Sprint(16*8 + []int{7, 6, 4, 3}[i])
+		if m[1] != expectedPri || m[2] != host || m[3] != fmt.Sprint(os.Getpid()) || m[5] != "diwe"[i:i+1] {
+			t.Fatalf("the RFC 5424 message is not formatted as expected. i: %d, message: %s", i, msg)
+		}
+	}
+}
+
+func TestSyslogSink_Unix(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	pc, err := net.ListenPacket("unixgram", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	l := newSyslogLogger(t, "syslog://"+path+"?format=rfc3164")
+	l.Warn("hello")
+
+	msg := readDatagrams(t, pc, 1)[0]
+	re := regexp.MustCompile(`^<12>\w{3} [ \d]\d \d\d:\d\d:\d\d \S+ \S+\[\d+\]: L=WARN M=hello$`)
+	if !re.MatchString(msg) {
+		t.Fatal("the RFC 3164 message is not formatted as expected: " + msg)
+	}
+}
+
+func TestSyslogSink_UnixStream(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "log.sock")
+	ln, err := net.Listen("unix", path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	l := newSyslogLogger(t, "syslog://"+path)
+	l.Info("first")
+	l.Info("second")
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+	for _, expected := range []string{"M=first\n", "M=second\n"} {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(line, "<14>1 ") || !strings.HasSuffix(line, expected) {
+			t.Fatal("messages over a unix stream socket are not framed as expected: " + line)
+		}
+	}
+}
+
+func TestSyslogSink_TCP(t *testing.T) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ln.Close()
+
+	l := newSyslogLogger(t, "syslog://"+ln.Addr().String()+"?transport=tcp&app=myapp")
+	l.Error("first")
+	l.Info("second message")
+
+	conn, err := ln.Accept()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	r := bufio.NewReader(conn)
+	for _, expected := range []string{"<11>1 ", "<14>1 "} {
+		var n int
+		if _, err := fmt.Fscanf(r, "%d ", &n); err != nil {
+			t.Fatal(err)
+		}
+		buf := make([]byte, n)
+		if _, err := io.ReadFull(r, buf); err != nil {
+			t.Fatal(err)
+		}
+		if !strings.HasPrefix(string(buf), expected) || !strings.Contains(string(buf), " myapp ") {
+			t.Fatal("messages over TCP are not framed as expected: " + string(buf))
+		}
+	}
+}
+
+func TestSyslogSink_Sampling(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	cfg := NewProductionConfig()
+	cfg.OutputPaths = []string{"syslog://" + pc.LocalAddr().String()}
+	l := cfg.MustBuild()
+	for i := 0; i < 1000; i++ {
+		l.Info("same")
+	}
+
+	var n int
+	buf := make([]byte, 65536)
+	for {
+		_ = pc.SetReadDeadline(time.Now().Add(500 * time.Millisecond))
+		if _, _, err := pc.ReadFrom(buf); err != nil {
+			break
+		}
+		n++
+	}
+	if n == 0 || n >= 1000 {
+		t.Fatalf("the entries should be sampled. n: %d", n)
+	}
+}
+
+func TestSyslogSink_ZapConfig(t *testing.T) {
+	pc, err := net.ListenPacket("udp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pc.Close()
+
+	extensionRegistry.Do(registerExtensions)
+	cfg := zap.NewProductionConfig()
+	cfg.OutputPaths = []string{"syslog://" + pc.LocalAddr().String() + "?severity=3"}
+	l, err := cfg.Build()
+	if err != nil {
+		t.Fatal(err)
+	}
+	l.Info("hello")
+
+	if msg := readDatagrams(t, pc, 1)[0]; !strings.HasPrefix(msg, "<11>1 ") || !strings.Contains(msg, `"msg":"hello"`) {
+		t.Fatal("the syslog sink does not work as expected: " + msg)
+	}
+}
+
+func TestSyslogSink_InvalidURL(t *testing.T) {
+	for _, url := range []string{
+		"syslog://127.0.0.1:514?transport=sctp",
+		"syslog://127.0.0.1:514?format=rfc9999",
+		"syslog://127.0.0.1:514?facility=local9",
+		"syslog://",
+	} {
+		cfg := NewProductionConfig()
+		cfg.OutputPaths = []string{url}
+		if _, err := cfg.Build(); err == nil {
+			t.Fatal("an error is expected. url: " + url)
+		}
+	}
+}
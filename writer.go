@@ -0,0 +1,143 @@
+package slog
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+const defaultMaxLineLength = 64 * 1024
+
+var (
+	_ io.WriteCloser = &Writer{}
+)
+
+type writerOptions struct {
+	maxLineLength int
+	keyVals       []any
+}
+
+// WriterOption configures a Writer.
+type WriterOption func(opts *writerOptions)
+
+// WriterMaxLineLength sets the maximum length of a line. Longer lines are split into
+// multiple log messages. The default length is 64 KiB.
+func WriterMaxLineLength(n int) WriterOption {
+	return func(opts *writerOptions) {
+		opts.maxLineLength = n
+	}
+}
+
+// WriterFields adds fields to every log message written by a Writer. The variadic
+// key-value pairs are treated as they are in NewLoggerWith.
+func WriterFields(keyVals ...any) WriterOption {
+	return func(opts *writerOptions) {
+		opts.keyVals = append(opts.keyVals, keyVals...)
+	}
+}
+
+// Writer is an io.Writer that splits its input into lines and logs each line as a
+// log message. It is useful for logging the output of subprocesses and libraries that
+// accept an io.Writer.
+type Writer struct {
+	mu     sync.Mutex
+	l      Logger
+	level  int
+	maxLen int
+	buf    []byte
+	// split reports whether the beginning of the buffered line has been logged
+	// because the line is too long.
+	split bool
+}
+
+// writerCallerSkip makes a *ZapLogger report the caller of Write or Close rather than
+// logAt by skipping logAt, Writer.emit, Writer.emitLine or Writer.emitOverflow, and
+// Writer.Write or Writer.Close.
+const writerCallerSkip = 4
+
+// NewWriter creates a new Writer that logs each line to l at level, e.g. ZapInfoLevel.
+func NewWriter(l Logger, level int, opts ...WriterOption) *Writer {
+	options := writerOptions{
+		maxLineLength: defaultMaxLineLength,
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+	if options.maxLineLength <= 0 {
+		options.maxLineLength = defaultMaxLineLength
+	}
+	if len(options.keyVals) > 0 {
+		l = l.NewLoggerWith(options.keyVals...)
+	}
+	return &Writer{
+		l:      AddCallerSkip(l, writerCallerSkip),
+		level:  level,
+		maxLen: options.maxLineLength,
+	}
+}
+
+// Write logs every complete line in p. An incomplete trailing line is buffered until
+// the rest of it arrives, it grows beyond the maximum line length, or Close is called.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	n := len(p)
+	for len(p) > 0 {
+		idx := bytes.IndexByte(p, '\n')
+		if idx < 0 {
+			w.buf = append(w.buf, p...)
+			w.emitOverflow()
+			break
+		}
+
+		line := p[:idx]
+		if len(w.buf) > 0 {
+			w.buf = append(w.buf, line...)
+			line = w.buf
+		}
+		w.emitLine(line)
+		w.buf = w.buf[:0]
+		p = p[idx+1:]
+	}
+	return n, nil
+}
+
+// emitOverflow logs the beginning of the buffered incomplete line while it is longer
+// than the maximum line length.
+func (w *Writer) emitOverflow() {
+	for len(w.buf) > w.maxLen {
+		w.emit(w.buf[:w.maxLen])
+		w.buf = append(w.buf[:0], w.buf[w.maxLen:]...)
+		w.split = true
+	}
+}
+
+// emitLine logs a complete line, split into chunks no longer than the maximum line
+// length. Nothing is logged for the empty rest of a line that has been split already.
+func (w *Writer) emitLine(line []byte) {
+	line = bytes.TrimSuffix(line, []byte("\r"))
+	for len(line) > w.maxLen {
+		w.emit(line[:w.maxLen])
+		line = line[w.maxLen:]
+	}
+	if len(line) > 0 || !w.split {
+		w.emit(line)
+	}
+	w.split = false
+}
+
+func (w *Writer) emit(line []byte) {
+	logAt(w.l, w.level, string(line))
+}
+
+// Close logs the buffered incomplete line, if any.
+func (w *Writer) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.buf) > 0 {
+		w.emitLine(w.buf)
+		w.buf = nil
+	}
+	return nil
+}
@@ -0,0 +1,115 @@
+package slog
+
+import (
+	"bytes"
+	"fmt"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+func TestWriter(t *testing.T) {
+	sc := NewScavenger()
+	w := NewWriter(sc, ZapWarnLevel, WriterFields("stream", "stderr"))
+	_, _ = fmt.Fprint(w, "hello ")
+	_, _ = fmt.Fprint(w, "world\r\nfoo\n\nbar")
+	if sc.Len() != 3 {
+		t.Fatal(`sc.Len() != 3`)
+	}
+	_ = w.Close()
+	_ = w.Close()
+
+	dump := `WARN	hello world	{"stream": "stderr"}
+WARN	foo	{"stream": "stderr"}
+WARN	{"stream": "stderr"}
+WARN	bar	{"stream": "stderr"}
+`
+	if sc.Dump() != dump {
+		t.Fatal("something is wrong with Writer: " + sc.Dump())
+	}
+}
+
+func TestWriter_MaxLineLength(t *testing.T) {
+	sc := NewScavenger()
+	w := NewWriter(sc, ZapInfoLevel, WriterMaxLineLength(4))
+	_, _ = w.Write([]byte("abcdefghij\n12"))
+	_, _ = w.Write([]byte("345"))
+	_ = w.Close()
+
+	dump := `INFO	abcd
+INFO	efgh
+INFO	ij
+INFO	1234
+INFO	5
+`
+	if sc.Dump() != dump {
+		t.Fatal("something is wrong with Writer: " + sc.Dump())
+	}
+}
+
+func TestWriter_Caller(t *testing.T) {
+	var buf bytes.Buffer
+	enc := zapcore.NewConsoleEncoder(zap.NewDevelopmentEncoderConfig())
+	core := zapcore.NewCore(enc, zapcore.AddSync(&buf), zapcore.DebugLevel)
+	w := NewWriter(NewZapLogger(zap.New(core, zap.AddCaller()).Sugar()), ZapInfoLevel, WriterMaxLineLength(4))
+	_, _ = w.Write([]byte("abcdef\n12"))
+	_, _ = w.Write([]byte("345"))
+	_ = w.Close()
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	if len(lines) != 4 {
+		t.Fatal("something is wrong with Writer: " + buf.String())
+	}
+	for _, line := range lines {
+		if !strings.Contains(line, "/writer_test.go:") {
+			t.Fatal("the caller of Writer should be reported: " + line)
+		}
+	}
+}
+
+func TestWriter_MaxLineLengthBoundary(t *testing.T) {
+	sc := NewScavenger()
+	w := NewWriter(sc, ZapInfoLevel, WriterMaxLineLength(4))
+	_, _ = w.Write([]byte("abcd"))
+	_, _ = w.Write([]byte("\n"))
+	_, _ = w.Write([]byte("efghi"))
+	_, _ = w.Write([]byte("\r"))
+	_, _ = w.Write([]byte("\n"))
+	_, _ = w.Write([]byte("\n"))
+	_, _ = w.Write([]byte("wxyz"))
+	_, _ = w.Write([]byte("\r"))
+	_, _ = w.Write([]byte("\n"))
+	_, _ = w.Write([]byte("jklmn"))
+	_ = w.Close()
+
+	dump := `INFO	abcd
+INFO	efgh
+INFO	i
+INFO	
+INFO	wxyz
+INFO	jklm
+INFO	n
+`
+	if sc.Dump() != dump {
+		t.Fatal("something is wrong with Writer: " + sc.Dump())
+	}
+}
+
+func TestWriter_Subprocess(t *testing.T) {
+	if _, err := exec.LookPath("sh"); err != nil {
+		t.Skip("sh is not available")
+	}
+	sc := NewScavenger()
+	stdout := NewWriter(sc, ZapInfoLevel)
+	cmd := exec.Command("sh", "-c", "echo line1; echo line2")
+	cmd.Stdout = stdout
+	if err := cmd.Run(); err != nil {
+		t.Fatal(err)
+	}
+	_ = stdout.Close()
+	if !sc.SequenceExists([]string{"line1", "line2"}) || sc.Len() != 2 {
+		t.Fatal("something is wrong with Writer: " + sc.Dump())
+	}
+}
@@ -16,18 +16,39 @@ var (
 	_ Logger = &ZapLogger{}
 )
 
+type zapLoggerOptions struct {
+	structuredErrors bool
+}
+
+// ZapLoggerOption configures a ZapLogger.
+type ZapLoggerOption func(opts *zapLoggerOptions)
+
+// ZapLoggerStructuredErrors makes a ZapLogger render the errors passed to its w-methods
+// and NewLoggerWith as structured fields, including the type name, the causes and the
+// stack trace. By default, an error is rendered as its message, as zap does.
+func ZapLoggerStructuredErrors() ZapLoggerOption {
+	return func(opts *zapLoggerOptions) {
+		opts.structuredErrors = true
+	}
+}
+
 // ZapLogger is a wrapper of zap.SugaredLogger.
 type ZapLogger struct {
-	x zap.SugaredLogger
-	l zap.Logger
+	x    zap.SugaredLogger
+	l    zap.Logger
+	opts zapLoggerOptions
 }
 
 // NewZapLogger creates a new ZapLogger.
-func NewZapLogger(zsl *zap.SugaredLogger) *ZapLogger {
-	return &ZapLogger{
+func NewZapLogger(zsl *zap.SugaredLogger, opts ...ZapLoggerOption) *ZapLogger {
+	zl := &ZapLogger{
 		x: *zsl.WithOptions(zap.AddCallerSkip(1)),
 		l: *zsl.Desugar(),
 	}
+	for _, opt := range opts {
+		opt(&zl.opts)
+	}
+	return zl
 }
 
 // Zap returns the internal zap.Logger to the caller.
@@ -35,9 +56,38 @@ func (zl *ZapLogger) Zap() *zap.Logger {
 	return &zl.l
 }
 
+// WithCallerSkip returns a copy of zl that skips skip more frames when reporting the
+// caller. It is useful for adapters that log on behalf of their callers.
+func (zl *ZapLogger) WithCallerSkip(skip int) *ZapLogger {
+	return &ZapLogger{
+		x:    *zl.x.WithOptions(zap.AddCallerSkip(skip)),
+		l:    *zl.l.WithOptions(zap.AddCallerSkip(skip)),
+		opts: zl.opts,
+	}
+}
+
+// AddCallerSkip returns l.WithCallerSkip(skip) if l is a *ZapLogger, or l otherwise.
+func AddCallerSkip(l Logger, skip int) Logger {
+	if zl, ok := l.(*ZapLogger); ok {
+		return zl.WithCallerSkip(skip)
+	}
+	return l
+}
+
 func (zl *ZapLogger) NewLoggerWith(keyVals ...any) Logger {
-	zsl := zl.x.With(keyVals...).WithOptions(zap.AddCallerSkip(-1))
-	return NewZapLogger(zsl)
+	zsl := zl.x.With(zl.renderErrors(keyVals)...).WithOptions(zap.AddCallerSkip(-1))
+	return &ZapLogger{
+		x:    *zsl.WithOptions(zap.AddCallerSkip(1)),
+		l:    *zsl.Desugar(),
+		opts: zl.opts,
+	}
+}
+
+func (zl *ZapLogger) renderErrors(keyVals []any) []any {
+	if !zl.opts.structuredErrors {
+		return keyVals
+	}
+	return renderErrors(keyVals)
 }
 
 func (zl *ZapLogger) LogLevelEnabled(level int) bool {
@@ -77,19 +127,19 @@ func (zl *ZapLogger) Errorf(format string, args ...any) {
 }
 
 func (zl *ZapLogger) Debugw(msg string, keyVals ...any) {
-	zl.x.Debugw(msg, keyVals...)
+	zl.x.Debugw(msg, zl.renderErrors(keyVals)...)
 }
 
 func (zl *ZapLogger) Infow(msg string, keyVals ...any) {
-	zl.x.Infow(msg, keyVals...)
+	zl.x.Infow(msg, zl.renderErrors(keyVals)...)
 }
 
 func (zl *ZapLogger) Warnw(msg string, keyVals ...any) {
-	zl.x.Warnw(msg, keyVals...)
+	zl.x.Warnw(msg, zl.renderErrors(keyVals)...)
 }
 
 func (zl *ZapLogger) Errorw(msg string, keyVals ...any) {
-	zl.x.Errorw(msg, keyVals...)
+	zl.x.Errorw(msg, zl.renderErrors(keyVals)...)
 }
 
 func (zl *ZapLogger) FlushLogger() error {